@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Sanitizer is implemented by configurations able to set default values and validate
+themselves, such as the Config of every integration.
+*/
+type Sanitizer interface {
+	Sanitize() error
+}
+
+/*
+Load populates the configuration passed, which must be a pointer to a struct. Values
+are loaded in the following order, each source overriding the previous ones:
+
+ 1. The values already set in the configuration, acting as defaults;
+ 2. The JSON and YAML files passed with WithFiles, in order;
+ 3. The environment variables.
+
+If the configuration implements Sanitizer, it is then sanitized. Every problem
+encountered — such as an unknown field in a file, a value that can not be parsed,
+or a validation failure — is reported at once in the error returned.
+
+Example:
+
+	var cfg postgres.Config
+	err := config.Load(&cfg,
+	  config.WithPrefix("POSTGRES"),
+	  config.WithFiles("./config.yaml"),
+	  config.WithKey("postgres"),
+	)
+*/
+func Load(cfg any, opts ...With) error {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	stack := errorstack.New("Failed to load configuration")
+
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Configuration must be a non-nil pointer to a struct",
+		})
+
+		return stack
+	}
+
+	d := &decoder{
+		stack: stack,
+	}
+
+	root := []string{rv.Elem().Type().Name()}
+	for _, path := range options.files {
+		tree, err := readFile(path)
+		if err != nil {
+			stack.WithValidations(errorstack.Validation{
+				Message: err.Error(),
+			})
+
+			continue
+		}
+
+		tree, exists := lookup(tree, options.key)
+		if !exists {
+			continue
+		}
+
+		d.fromTree(rv.Elem(), tree, root, path)
+	}
+
+	d.fromEnv(rv.Elem(), options.prefix, root)
+
+	sanitizer, ok := cfg.(Sanitizer)
+	if ok {
+		if err := sanitizer.Sanitize(); err != nil {
+			var invalid *errorstack.Error
+			if errors.As(err, &invalid) {
+				stack.Integration = invalid.Integration
+				stack.WithValidations(invalid.Validations...)
+				stack.WithChildren(invalid.Children...)
+			} else {
+				stack.WithChildren(err)
+			}
+		}
+	}
+
+	if stack.HasValidations() || stack.HasChildren() {
+		return stack
+	}
+
+	return nil
+}
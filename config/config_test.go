@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Config struct {
+	Address   string            `json:"address"`
+	Password  string            `json:"-" config:"password"`
+	Timeout   time.Duration     `json:"timeout"`
+	Retries   int               `json:"retries"`
+	DependsOn []string          `json:"depends_on,omitempty"`
+	Labels    map[string]string `json:"labels"`
+	TLS       ConfigTLS         `json:"tls"`
+	Worker    *ConfigWorker     `json:"worker"`
+	Handler   func()            `json:"-"`
+}
+
+type ConfigTLS struct {
+	Enabled    bool   `json:"enabled"`
+	ServerName string `json:"server_name,omitempty"`
+}
+
+type ConfigWorker struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (cfg *Config) Sanitize() error {
+	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration("test"))
+
+	if cfg.Address == "" {
+		cfg.Address = ":8080"
+	}
+
+	if cfg.TLS.Enabled && cfg.TLS.ServerName == "" {
+		stack.WithValidations(errorstack.Validation{
+			Message: "ServerName must be set and not be empty",
+			Path:    []string{"Config", "TLS", "ServerName"},
+		})
+	}
+
+	if stack.HasValidations() {
+		return stack
+	}
+
+	return nil
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yaml := writeFile(t, "config.yaml", `
+test:
+  address: "127.0.0.1:4222"
+  timeout: 5s
+  retries: 3
+  depends_on:
+    - postgres
+  labels:
+    team: core
+  tls:
+    enabled: true
+    server_name: "localhost"
+`)
+
+	json := writeFile(t, "config.json", `{
+  "test": {
+    "retries": 5,
+    "worker": {
+      "enabled": true
+    }
+  }
+}`)
+
+	t.Setenv("TEST_PASSWORD", "secret")
+	t.Setenv("TEST_DEPENDS_ON", "postgres, nats")
+	t.Setenv("TEST_TLS_SERVER_NAME", "example.com")
+
+	cfg := Config{
+		Timeout: time.Second,
+	}
+
+	err := Load(&cfg, WithPrefix("TEST"), WithFiles(yaml, json), WithKey("test"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:4222", cfg.Address)
+	assert.Equal(t, "secret", cfg.Password)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, 5, cfg.Retries)
+	assert.Equal(t, []string{"postgres", "nats"}, cfg.DependsOn)
+	assert.Equal(t, map[string]string{"team": "core"}, cfg.Labels)
+	assert.Equal(t, ConfigTLS{Enabled: true, ServerName: "example.com"}, cfg.TLS)
+	assert.Equal(t, &ConfigWorker{Enabled: true}, cfg.Worker)
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	var cfg Config
+	err := Load(&cfg, WithPrefix("TEST_DEFAULTS"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.Address)
+	assert.Nil(t, cfg.Worker)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	yaml := writeFile(t, "config.yaml", `
+timeout: forever
+unknown: true
+tls:
+  enabled: true
+`)
+
+	t.Setenv("TEST_ERRORS_RETRIES", "three")
+
+	var cfg Config
+	err := Load(&cfg, WithPrefix("TEST_ERRORS"), WithFiles(yaml, "./missing.toml"))
+
+	expected := &errorstack.Error{
+		Integration: "test",
+		Message:     "Failed to load configuration",
+		Validations: []errorstack.Validation{
+			{
+				Message: `Failed to parse "forever" from file "` + yaml + `": time: invalid duration "forever"`,
+				Path:    []string{"Config", "Timeout"},
+			},
+			{
+				Message: `Unknown field "unknown" in file "` + yaml + `"`,
+				Path:    []string{"Config", "unknown"},
+			},
+			{
+				Message: `Failed to read file "./missing.toml": extension must be one of .json, .yaml, .yml`,
+			},
+			{
+				Message: `Failed to parse "three" from environment variable "TEST_ERRORS_RETRIES": must be an integer`,
+				Path:    []string{"Config", "Retries"},
+			},
+			{
+				Message: "ServerName must be set and not be empty",
+				Path:    []string{"Config", "TLS", "ServerName"},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, err)
+}
+
+func TestLoad_NotStruct(t *testing.T) {
+	var cfg string
+	err := Load(&cfg)
+
+	expected := &errorstack.Error{
+		Message: "Failed to load configuration",
+		Validations: []errorstack.Validation{
+			{
+				Message: "Configuration must be a non-nil pointer to a struct",
+			},
+		},
+	}
+
+	assert.Equal(t, expected, err)
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Types handled specifically when decoding values.
+*/
+var (
+	typeDuration        = reflect.TypeFor[time.Duration]()
+	typeTextUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+/*
+decoder decodes values into a configuration, and adds a validation failure to its
+error for each value that can not be decoded.
+*/
+type decoder struct {
+	stack *errorstack.Error
+}
+
+/*
+fail adds a validation failure at the path passed.
+*/
+func (d *decoder) fail(path []string, format string, args ...any) {
+	d.stack.WithValidations(errorstack.Validation{
+		Message: fmt.Sprintf(format, args...),
+		Path:    slices.Clone(path),
+	})
+}
+
+/*
+field is a field of a struct that can be loaded.
+*/
+type field struct {
+
+	// index is the index of the field in the struct.
+	index int
+
+	// name is the name of the field in files and environment variables, as found
+	// in its tags.
+	name string
+
+	// path is the name of the field in Go, used in the path of validation failures.
+	path string
+}
+
+/*
+fieldsOf returns the fields of the struct type passed that can be loaded. A field
+is named after its `config` tag if any, or its `json` tag otherwise. Fields not
+exported or excluded from JSON with no `config` tag are ignored.
+*/
+func fieldsOf(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get("config"), ",")
+		if name == "" {
+			name, _, _ = strings.Cut(sf.Tag.Get("json"), ",")
+		}
+
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(sf.Name)
+		}
+
+		fields = append(fields, field{
+			index: i,
+			name:  name,
+			path:  sf.Name,
+		})
+	}
+
+	return fields
+}
+
+/*
+isObject informs if values of the type passed are decoded from objects, and not
+from scalar values.
+*/
+func isObject(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(typeTextUnmarshaler) {
+		return false
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+/*
+fromTree decodes a value parsed from a file into v.
+*/
+func (d *decoder) fromTree(v reflect.Value, raw any, path []string, file string) {
+	if raw == nil {
+		return
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		d.fromTree(v.Elem(), raw, path, file)
+		return
+	}
+
+	switch {
+	case isObject(v.Type()):
+		object, ok := raw.(map[string]any)
+		if !ok {
+			d.fail(path, "Failed to parse value from file %q: must be an object", file)
+			return
+		}
+
+		fields := fieldsOf(v.Type())
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+
+		slices.Sort(keys)
+		for _, key := range keys {
+			i := slices.IndexFunc(fields, func(f field) bool {
+				return f.name == key
+			})
+
+			if i < 0 {
+				d.fail(append(path, key), "Unknown field %q in file %q", key, file)
+				continue
+			}
+
+			d.fromTree(v.Field(fields[i].index), object[key], append(path, fields[i].path), file)
+		}
+
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		object, ok := raw.(map[string]any)
+		if !ok {
+			d.fail(path, "Failed to parse value from file %q: must be an object", file)
+			return
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(object)))
+		}
+
+		for key, value := range object {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			d.fromTree(elem, value, append(path, key), file)
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+
+	case v.Kind() == reflect.Slice:
+		if s, ok := scalar(raw); ok {
+			if err := parse(v, s); err != nil {
+				d.fail(path, "Failed to parse %q from file %q: %v", s, file, err)
+			}
+
+			return
+		}
+
+		list, ok := raw.([]any)
+		if !ok {
+			d.fail(path, "Failed to parse value from file %q: must be a list", file)
+			return
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, value := range list {
+			d.fromTree(slice.Index(i), value, append(path, strconv.Itoa(i)), file)
+		}
+
+		v.Set(slice)
+
+	default:
+		s, ok := scalar(raw)
+		if !ok {
+			d.fail(path, "Failed to parse value from file %q: must be a scalar value", file)
+			return
+		}
+
+		if err := parse(v, s); err != nil {
+			d.fail(path, "Failed to parse %q from file %q: %v", s, file, err)
+		}
+	}
+}
+
+/*
+fromEnv decodes the environment variables starting with the prefix passed into
+the fields of the struct v. Returns true if at least one variable has been found.
+*/
+func (d *decoder) fromEnv(v reflect.Value, prefix string, path []string) bool {
+	var found bool
+	for _, f := range fieldsOf(v.Type()) {
+		name := strings.ToUpper(f.name)
+		name = strings.NewReplacer("-", "_", ".", "_").Replace(name)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		fv := v.Field(f.index)
+		ft := fv.Type()
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if isObject(ft) {
+			// A nil pointer is only set if at least one variable has been found.
+			var nested reflect.Value
+			switch {
+			case fv.Kind() != reflect.Pointer:
+				nested = fv.Addr()
+			case fv.IsNil():
+				nested = reflect.New(ft)
+			default:
+				nested = fv
+			}
+
+			if d.fromEnv(nested.Elem(), name, append(path, f.path)) {
+				found = true
+				if fv.Kind() == reflect.Pointer {
+					fv.Set(nested)
+				}
+			}
+
+			continue
+		}
+
+		switch ft.Kind() {
+		case reflect.Func, reflect.Chan, reflect.Interface, reflect.Map, reflect.UnsafePointer:
+			continue
+		}
+
+		value, exists := os.LookupEnv(name)
+		if !exists {
+			continue
+		}
+
+		found = true
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				fv.Set(reflect.New(ft))
+			}
+
+			fv = fv.Elem()
+		}
+
+		if err := parse(fv, value); err != nil {
+			d.fail(append(path, f.path), "Failed to parse %q from environment variable %q: %v", value, name, err)
+		}
+	}
+
+	return found
+}
+
+/*
+scalar returns the string representation of a scalar value parsed from a file.
+Returns false if the value is not a scalar one.
+*/
+func scalar(raw any) (string, bool) {
+	switch value := raw.(type) {
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool, int, int64, uint64:
+		return fmt.Sprint(value), true
+	case time.Time:
+		return value.Format(time.RFC3339Nano), true
+	}
+
+	return "", false
+}
+
+/*
+parse parses the string passed into v, given its type. Lists are comma-separated.
+*/
+func parse(v reflect.Value, s string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(typeTextUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if v.Type() == typeDuration {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+
+		v.SetFloat(f)
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return parse(v.Elem(), s)
+
+	case reflect.Slice:
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := parse(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+
+	default:
+		return fmt.Errorf("type %s is not supported", v.Type())
+	}
+
+	return nil
+}
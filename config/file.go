@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+readFile reads and parses the JSON or YAML file at the path passed, given its
+extension.
+*/
+func readFile(path string) (any, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("Failed to read file %q: extension must be one of .json, .yaml, .yml", path)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file %q: %v", path, err)
+	}
+
+	var tree any
+	if ext == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		err = dec.Decode(&tree)
+	} else {
+		err = yaml.Unmarshal(b, &tree)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse file %q: %v", path, err)
+	}
+
+	return tree, nil
+}
+
+/*
+lookup returns the value found in the tree at the key passed. Returns false if the
+key doesn't exist in the tree.
+*/
+func lookup(tree any, key []string) (any, bool) {
+	for _, k := range key {
+		object, ok := tree.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		tree, ok = object[k]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return tree, tree != nil
+}
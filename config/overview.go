@@ -0,0 +1,14 @@
+/*
+Package config allows to load the configuration of integrations — or any other
+struct — from environment variables, JSON and YAML files, and default values. The
+configuration is then validated, and every problem encountered is reported at once
+in a single error.
+
+Fields are named after their `json` tag. Fields excluded from JSON — such as
+credentials — can still be loaded by setting a `config` tag. Environment variables
+are named after the path of the field, in upper case, and joined with underscores:
+
+	POSTGRES_ADDRESS
+	POSTGRES_TLS_SERVER_NAME
+*/
+package config
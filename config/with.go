@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+)
+
+/*
+options holds the options set when loading a configuration with Load.
+*/
+type options struct {
+
+	// prefix is the prefix of environment variables.
+	prefix string
+
+	// files are the paths of the JSON and YAML files to load, in order.
+	files []string
+
+	// key is the path of the configuration within files, split by dots.
+	key []string
+}
+
+/*
+With allows to set optional values when loading a configuration with Load.
+*/
+type With func(opts *options)
+
+/*
+WithPrefix sets the prefix of environment variables. It is joined to the name of
+each variable with an underscore.
+
+Example:
+
+	config.WithPrefix("ORDERS_POSTGRES")
+
+Allows to load the field Address from:
+
+	ORDERS_POSTGRES_ADDRESS
+*/
+func WithPrefix(prefix string) With {
+	return func(opts *options) {
+		opts.prefix = prefix
+	}
+}
+
+/*
+WithFiles adds JSON or YAML files to load the configuration from. The format is
+detected from the extension of each file: ".json", ".yaml", or ".yml". Files are
+loaded in the order they are passed, so a file overrides the values of the previous
+ones.
+
+Example:
+
+	config.WithFiles("./config.yaml", "./config.production.yaml")
+*/
+func WithFiles(paths ...string) With {
+	return func(opts *options) {
+		opts.files = append(opts.files, paths...)
+	}
+}
+
+/*
+WithKey sets the path of the configuration within files, such as when a single
+file holds the configuration of several integrations. Nested keys are separated
+by dots.
+
+Example:
+
+	config.WithKey("integrations.postgres")
+
+Allows to load the configuration from:
+
+	integrations:
+	  postgres:
+	    address: "127.0.0.1:5432"
+*/
+func WithKey(key string) With {
+	return func(opts *options) {
+		if key != "" {
+			opts.key = strings.Split(key, ".")
+		}
+	}
+}
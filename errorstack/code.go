@@ -0,0 +1,262 @@
+package errorstack
+
+import (
+	"context"
+	"errors"
+)
+
+/*
+Code is a machine-readable code of an error.
+*/
+type Code string
+
+/*
+Canonical codes an error can have. They are based on the canonical codes of gRPC,
+so they can be mapped to both HTTP and gRPC status codes. See HTTPStatus and
+GRPCCode.
+*/
+const (
+
+	// CodeCanceled indicates the operation was canceled, typically by the caller.
+	CodeCanceled Code = "canceled"
+
+	// CodeUnknown indicates an unknown error. This is the code of errors without
+	// code.
+	CodeUnknown Code = "unknown"
+
+	// CodeInvalidArgument indicates the caller specified an invalid argument, such
+	// as a malformed request payload.
+	CodeInvalidArgument Code = "invalid_argument"
+
+	// CodeDeadlineExceeded indicates the deadline expired before the operation
+	// could complete.
+	CodeDeadlineExceeded Code = "deadline_exceeded"
+
+	// CodeNotFound indicates a requested resource was not found.
+	CodeNotFound Code = "not_found"
+
+	// CodeAlreadyExists indicates a resource the caller attempted to create already
+	// exists.
+	CodeAlreadyExists Code = "already_exists"
+
+	// CodeConflict indicates the operation conflicts with the current state of the
+	// target resource, such as a unique constraint violation.
+	CodeConflict Code = "conflict"
+
+	// CodePermissionDenied indicates the caller doesn't have the permission to
+	// execute the operation.
+	CodePermissionDenied Code = "permission_denied"
+
+	// CodeResourceExhausted indicates some resource has been exhausted, such as a
+	// rate limit or a quota.
+	CodeResourceExhausted Code = "resource_exhausted"
+
+	// CodeFailedPrecondition indicates the operation was rejected because the
+	// system is not in a state required for the operation's execution.
+	CodeFailedPrecondition Code = "failed_precondition"
+
+	// CodeAborted indicates the operation was aborted, typically due to a
+	// concurrency issue such as a transaction abort. The operation can be retried.
+	CodeAborted Code = "aborted"
+
+	// CodeOutOfRange indicates the operation was attempted past the valid range.
+	CodeOutOfRange Code = "out_of_range"
+
+	// CodeUnimplemented indicates the operation is not implemented or not supported.
+	CodeUnimplemented Code = "unimplemented"
+
+	// CodeInternal indicates an internal error.
+	CodeInternal Code = "internal"
+
+	// CodeUnavailable indicates the service is currently unavailable. This is most
+	// likely a transient condition, so the operation can be retried.
+	CodeUnavailable Code = "unavailable"
+
+	// CodeDataLoss indicates unrecoverable data loss or corruption.
+	CodeDataLoss Code = "data_loss"
+
+	// CodeUnauthenticated indicates the caller doesn't have valid authentication
+	// credentials for the operation.
+	CodeUnauthenticated Code = "unauthenticated"
+)
+
+/*
+mapping holds the HTTP and gRPC status codes of a code.
+*/
+type mapping struct {
+	http int
+	grpc uint32
+}
+
+/*
+mappings maps the canonical codes to their HTTP and gRPC status codes. gRPC status
+codes are the values of google.golang.org/grpc/codes, which is not imported to
+avoid a dependency.
+*/
+var mappings = map[Code]mapping{
+	CodeCanceled:           {http: 499, grpc: 1},
+	CodeUnknown:            {http: 500, grpc: 2},
+	CodeInvalidArgument:    {http: 400, grpc: 3},
+	CodeDeadlineExceeded:   {http: 504, grpc: 4},
+	CodeNotFound:           {http: 404, grpc: 5},
+	CodeAlreadyExists:      {http: 409, grpc: 6},
+	CodePermissionDenied:   {http: 403, grpc: 7},
+	CodeResourceExhausted:  {http: 429, grpc: 8},
+	CodeFailedPrecondition: {http: 400, grpc: 9},
+	CodeAborted:            {http: 409, grpc: 10},
+	CodeOutOfRange:         {http: 400, grpc: 11},
+	CodeUnimplemented:      {http: 501, grpc: 12},
+	CodeInternal:           {http: 500, grpc: 13},
+	CodeUnavailable:        {http: 503, grpc: 14},
+	CodeDataLoss:           {http: 500, grpc: 15},
+	CodeUnauthenticated:    {http: 401, grpc: 16},
+	CodeConflict:           {http: 409, grpc: 10},
+}
+
+/*
+HTTPStatus returns the HTTP status code of the code. Returns 500 if the code is
+not a canonical one.
+*/
+func (code Code) HTTPStatus() int {
+	m, ok := mappings[code]
+	if !ok {
+		return mappings[CodeUnknown].http
+	}
+
+	return m.http
+}
+
+/*
+GRPCCode returns the gRPC status code of the code, as defined by the package
+google.golang.org/grpc/codes. Returns 2 (Unknown) if the code is not a canonical
+one.
+
+Example:
+
+	codes.Code(err.Code.GRPCCode())
+*/
+func (code Code) GRPCCode() uint32 {
+	m, ok := mappings[code]
+	if !ok {
+		return mappings[CodeUnknown].grpc
+	}
+
+	return m.grpc
+}
+
+/*
+CodeFromHTTPStatus returns the canonical code of the HTTP status code passed.
+Returns an empty code if the status code is not an error.
+*/
+func CodeFromHTTPStatus(status int) Code {
+	switch status {
+	case 400:
+		return CodeInvalidArgument
+	case 401:
+		return CodeUnauthenticated
+	case 403:
+		return CodePermissionDenied
+	case 404:
+		return CodeNotFound
+	case 409:
+		return CodeConflict
+	case 412:
+		return CodeFailedPrecondition
+	case 429:
+		return CodeResourceExhausted
+	case 499:
+		return CodeCanceled
+	case 501:
+		return CodeUnimplemented
+	case 503:
+		return CodeUnavailable
+	case 504:
+		return CodeDeadlineExceeded
+	}
+
+	switch {
+	case status >= 400 && status < 500:
+		return CodeInvalidArgument
+	case status >= 500 && status < 600:
+		return CodeInternal
+	case status < 400:
+		return ""
+	}
+
+	return CodeUnknown
+}
+
+/*
+CodeFromGRPCCode returns the canonical code of the gRPC status code passed, as
+defined by the package google.golang.org/grpc/codes. Returns an empty code if the
+status code is 0 (OK).
+*/
+func CodeFromGRPCCode(grpc uint32) Code {
+	if grpc == 0 {
+		return ""
+	}
+
+	for code, m := range mappings {
+		if m.grpc == grpc && code != CodeConflict {
+			return code
+		}
+	}
+
+	return CodeUnknown
+}
+
+/*
+CodeOf returns the code of the first *Error with a code found in the tree of the
+error passed, using the same traversal as errors.As. If no code is found, errors
+of the context package are respectively classified as CodeDeadlineExceeded and
+CodeCanceled. Otherwise, CodeUnknown is returned. Returns an empty code if the
+error is nil.
+*/
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	if code := findCode(err); code != "" {
+		return code
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	}
+
+	return CodeUnknown
+}
+
+/*
+findCode returns the code of the first *Error with a code found in the tree of
+the error passed, if any.
+*/
+func findCode(err error) Code {
+	if e, ok := err.(*Error); ok && e.Code != "" {
+		return e.Code
+	}
+
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := x.Unwrap(); inner != nil {
+			return findCode(inner)
+		}
+
+	case interface{ Unwrap() []error }:
+		for _, inner := range x.Unwrap() {
+			if inner == nil {
+				continue
+			}
+
+			if code := findCode(inner); code != "" {
+				return code
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,160 @@
+package errorstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode_HTTPStatus(t *testing.T) {
+	testcases := []struct {
+		input    Code
+		expected int
+	}{
+		{
+			input:    CodeInvalidArgument,
+			expected: 400,
+		},
+		{
+			input:    CodeNotFound,
+			expected: 404,
+		},
+		{
+			input:    CodeConflict,
+			expected: 409,
+		},
+		{
+			input:    CodeUnavailable,
+			expected: 503,
+		},
+		{
+			input:    "custom",
+			expected: 500,
+		},
+		{
+			input:    "",
+			expected: 500,
+		},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, tc.input.HTTPStatus())
+	}
+}
+
+func TestCode_GRPCCode(t *testing.T) {
+	testcases := []struct {
+		input    Code
+		expected uint32
+	}{
+		{
+			input:    CodeNotFound,
+			expected: 5,
+		},
+		{
+			input:    CodeConflict,
+			expected: 10,
+		},
+		{
+			input:    CodeUnauthenticated,
+			expected: 16,
+		},
+		{
+			input:    "custom",
+			expected: 2,
+		},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, tc.input.GRPCCode())
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	testcases := []struct {
+		input    int
+		expected Code
+	}{
+		{
+			input:    200,
+			expected: "",
+		},
+		{
+			input:    404,
+			expected: CodeNotFound,
+		},
+		{
+			input:    422,
+			expected: CodeInvalidArgument,
+		},
+		{
+			input:    503,
+			expected: CodeUnavailable,
+		},
+		{
+			input:    502,
+			expected: CodeInternal,
+		},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, CodeFromHTTPStatus(tc.input))
+	}
+}
+
+func TestCodeFromGRPCCode(t *testing.T) {
+	for code := range mappings {
+		if code == CodeConflict {
+			continue
+		}
+
+		assert.Equal(t, code, CodeFromGRPCCode(code.GRPCCode()))
+	}
+
+	assert.Equal(t, CodeAborted, CodeFromGRPCCode(CodeConflict.GRPCCode()))
+	assert.Equal(t, Code(""), CodeFromGRPCCode(0))
+	assert.Equal(t, CodeUnknown, CodeFromGRPCCode(42))
+}
+
+func TestCodeOf(t *testing.T) {
+	leaf := NewFromError(errors.New("no rows in result set"), WithCode(CodeNotFound))
+	root := New("Failed to handle request")
+	root.WithChildren(errors.New("cache miss"), fmt.Errorf("wrapped: %w", leaf))
+
+	testcases := []struct {
+		input    error
+		expected Code
+	}{
+		{
+			input:    nil,
+			expected: "",
+		},
+		{
+			input:    root,
+			expected: CodeNotFound,
+		},
+		{
+			input:    New("Conflict", WithCode(CodeConflict)),
+			expected: CodeConflict,
+		},
+		{
+			input:    fmt.Errorf("query: %w", context.DeadlineExceeded),
+			expected: CodeDeadlineExceeded,
+		},
+		{
+			input:    context.Canceled,
+			expected: CodeCanceled,
+		},
+		{
+			input:    errors.New("connection reset"),
+			expected: CodeUnknown,
+		},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, CodeOf(tc.input))
+	}
+}
@@ -0,0 +1,182 @@
+package errorstack
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+/*
+Ensure *Error complies to zapcore.ObjectMarshaler type.
+*/
+var _ zapcore.ObjectMarshaler = (*Error)(nil)
+
+/*
+debugError is the complete tree of an error, including internal information that
+must not be exposed to clients, such as the integration, children errors, causes,
+and the call stack. It is only meant for debugging purposes, such as logging.
+*/
+type debugError struct {
+	Integration string        `json:"integration,omitempty"`
+	Code        Code          `json:"code,omitempty"`
+	Message     string        `json:"message"`
+	Validations []Validation  `json:"validations,omitempty"`
+	Children    []*debugError `json:"children,omitempty"`
+	Cause       *debugError   `json:"cause,omitempty"`
+	Stack       []string      `json:"stacktrace,omitempty"`
+}
+
+/*
+newDebugError returns the complete tree of the error passed. Errors that are not
+an *Error are also traversed, so an *Error wrapped with fmt.Errorf or errors.Join
+is not lost.
+*/
+func newDebugError(err error) *debugError {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := err.(*Error)
+	if !ok {
+		debug := &debugError{
+			Message: err.Error(),
+		}
+
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			debug.Cause = newDebugError(x.Unwrap())
+
+		case interface{ Unwrap() []error }:
+			for _, child := range x.Unwrap() {
+				if child != nil {
+					debug.Children = append(debug.Children, newDebugError(child))
+				}
+			}
+		}
+
+		return debug
+	}
+
+	debug := &debugError{
+		Integration: e.Integration,
+		Code:        e.Code,
+		Message:     e.Message,
+		Validations: e.Validations,
+		Cause:       newDebugError(e.Cause),
+	}
+
+	for _, child := range e.Children {
+		if child != nil {
+			debug.Children = append(debug.Children, newDebugError(child))
+		}
+	}
+
+	for _, frame := range e.StackTrace() {
+		debug.Stack = append(debug.Stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+	}
+
+	return debug
+}
+
+/*
+MarshalLogObject implements zapcore.ObjectMarshaler. Unlike the JSON encoding of
+the error, it includes the complete tree of the error: integration, validations,
+children and causes recursively, and the call stack if captured. This way, errors
+can be logged as structured objects instead of the multi-line string returned by
+Error.
+*/
+func (err *Error) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return newDebugError(err).MarshalLogObject(enc)
+}
+
+/*
+DebugJSON returns the JSON encoding of the complete tree of the error, just like
+MarshalLogObject. It must not be exposed to clients since it includes internal
+information.
+*/
+func (err *Error) DebugJSON() ([]byte, error) {
+	return json.Marshal(newDebugError(err))
+}
+
+/*
+MarshalLogObject implements zapcore.ObjectMarshaler.
+*/
+func (debug *debugError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if debug.Integration != "" {
+		enc.AddString("integration", debug.Integration)
+	}
+
+	if debug.Code != "" {
+		enc.AddString("code", string(debug.Code))
+	}
+
+	enc.AddString("message", debug.Message)
+
+	if len(debug.Validations) > 0 {
+		enc.AddArray("validations", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, validation := range debug.Validations {
+				arr.AppendObject(zapcore.ObjectMarshalerFunc(func(obj zapcore.ObjectEncoder) error {
+					obj.AddString("message", validation.Message)
+					if validation.Code != "" {
+						obj.AddString("code", validation.Code)
+					}
+
+					if len(validation.Params) > 0 {
+						if err := obj.AddReflected("params", validation.Params); err != nil {
+							return err
+						}
+					}
+
+					if len(validation.Path) > 0 {
+						return obj.AddArray("path", stringArray(validation.Path))
+					}
+
+					return nil
+				}))
+			}
+
+			return nil
+		}))
+	}
+
+	if len(debug.Children) > 0 {
+		enc.AddArray("children", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, child := range debug.Children {
+				if err := arr.AppendObject(child); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}))
+	}
+
+	if debug.Cause != nil {
+		if err := enc.AddObject("cause", debug.Cause); err != nil {
+			return err
+		}
+	}
+
+	if len(debug.Stack) > 0 {
+		enc.AddArray("stacktrace", stringArray(debug.Stack))
+	}
+
+	return nil
+}
+
+/*
+stringArray implements zapcore.ArrayMarshaler for a slice of strings.
+*/
+type stringArray []string
+
+/*
+MarshalLogArray implements zapcore.ArrayMarshaler.
+*/
+func (values stringArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for _, value := range values {
+		arr.AppendString(value)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package errorstack
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTreeForTest() *Error {
+	leaf := NewFromError(errors.New("no rows in result set"), WithIntegration("postgres"), WithCode(CodeNotFound))
+
+	root := New("Failed to handle request", WithIntegration("rest"))
+	root.WithValidations(Validation{
+		Message: "User does not exist",
+		Path:    []string{"request", "params", "id"},
+	})
+
+	root.WithChildren(fmt.Errorf("wrapped: %w", leaf))
+	return root
+}
+
+func TestError_DebugJSON(t *testing.T) {
+	b, err := newTreeForTest().DebugJSON()
+
+	expected := `{
+  "integration": "rest",
+  "message": "Failed to handle request",
+  "validations": [
+    {
+      "message": "User does not exist",
+      "path": ["request", "params", "id"]
+    }
+  ],
+  "children": [
+    {
+      "message": "wrapped: postgres: no rows in result set.",
+      "cause": {
+        "integration": "postgres",
+        "code": "not_found",
+        "message": "no rows in result set",
+        "cause": {
+          "message": "no rows in result set"
+        }
+      }
+    }
+  ]
+}`
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(b))
+}
+
+func TestError_MarshalLogObject(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	err := newTreeForTest().MarshalLogObject(enc)
+
+	expected := map[string]any{
+		"integration": "rest",
+		"message":     "Failed to handle request",
+		"validations": []any{
+			map[string]any{
+				"message": "User does not exist",
+				"path":    []any{"request", "params", "id"},
+			},
+		},
+		"children": []any{
+			map[string]any{
+				"message": "wrapped: postgres: no rows in result set.",
+				"cause": map[string]any{
+					"integration": "postgres",
+					"code":        "not_found",
+					"message":     "no rows in result set",
+					"cause": map[string]any{
+						"message": "no rows in result set",
+					},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, enc.Fields)
+}
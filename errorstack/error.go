@@ -26,6 +26,14 @@ type Error struct {
 	//   "vault"
 	Integration string `json:"-"`
 
+	// Code is the machine-readable code of the error, if any. Errors with the same
+	// code match when using errors.Is, which allows to declare sentinel errors.
+	//
+	// Example:
+	//
+	//   "not_found"
+	Code Code `json:"code,omitempty"`
+
 	// Message is the top-level message of the error.
 	Message string `json:"message,omitempty"`
 
@@ -38,6 +46,15 @@ type Error struct {
 	// error. Omit children errors when working with JSON: we don't want to give
 	// internal information to clients consuming HTTP APIs.
 	Children []error `json:"-"`
+
+	// Cause is the original error the error has been created from with NewFromError,
+	// if any. Omit cause when working with JSON: we don't want to give internal
+	// information to clients consuming HTTP APIs.
+	Cause error `json:"-"`
+
+	// stack holds the program counters of the call stack captured when the error
+	// has been created, if enabled. See SetStackCapture.
+	stack []uintptr
 }
 
 /*
@@ -48,6 +65,23 @@ type Validation struct {
 	// Message is the cause of the validation failure.
 	Message string `json:"message"`
 
+	// Code is the machine-readable code of the validation failure, if any. It
+	// allows clients to handle the failure, and integrations to translate the
+	// message, without relying on the message itself.
+	//
+	// Example:
+	//
+	//   "min_length"
+	Code string `json:"code,omitempty"`
+
+	// Params holds the values of the validation failure, if any. They can be used
+	// to render the message in another language.
+	//
+	// Example:
+	//
+	//   map[string]any{"min": 3}
+	Params map[string]any `json:"params,omitempty"`
+
 	// Path represents the path to the key where the validation failure occurred.
 	//
 	// Example:
@@ -63,6 +97,7 @@ func New(message string, opts ...With) *Error {
 	err := &Error{
 		Message:     message,
 		Validations: []Validation{},
+		stack:       callers(),
 	}
 
 	for _, opt := range opts {
@@ -73,7 +108,9 @@ func New(message string, opts ...With) *Error {
 }
 
 /*
-NewFromError returns a new error given the existing error and options passed.
+NewFromError returns a new error given the existing error and options passed. The
+existing error is kept as the cause, so it can still be matched with errors.Is
+and errors.As.
 */
 func NewFromError(existing error, opts ...With) *Error {
 	if existing == nil {
@@ -83,6 +120,8 @@ func NewFromError(existing error, opts ...With) *Error {
 	err := &Error{
 		Message:     existing.Error(),
 		Validations: []Validation{},
+		Cause:       existing,
+		stack:       callers(),
 	}
 
 	for _, opt := range opts {
@@ -165,3 +204,36 @@ func (err *Error) Error() string {
 
 	return msg
 }
+
+/*
+Unwrap returns the cause of the error, if any, followed by its children. This
+allows errors.Is and errors.As to traverse the whole tree of errors.
+*/
+func (err *Error) Unwrap() []error {
+	var errs []error
+	if err.Cause != nil {
+		errs = append(errs, err.Cause)
+	}
+
+	return append(errs, err.Children...)
+}
+
+/*
+Is informs if the error matches the target when using errors.Is. An error matches
+a target *Error with the same non-empty code, no matter their messages.
+
+Example:
+
+	var ErrNotFound = errorstack.New("Resource not found", errorstack.WithCode(errorstack.CodeNotFound))
+
+	err := errorstack.New("User not found", errorstack.WithCode(errorstack.CodeNotFound))
+	errors.Is(err, ErrNotFound) // true
+*/
+func (err *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return t.Code != "" && t.Code == err.Code
+}
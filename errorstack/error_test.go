@@ -1,6 +1,9 @@
 package errorstack
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,3 +69,107 @@ func TestError_Error(t *testing.T) {
 		assert.Equal(t, tc.expected, actual)
 	}
 }
+
+func TestNewFromError(t *testing.T) {
+	cause := errors.New("no rows in result set")
+
+	testcases := []struct {
+		input    *Error
+		expected *Error
+	}{
+		{
+			input:    NewFromError(nil),
+			expected: nil,
+		},
+		{
+			input: NewFromError(cause, WithIntegration("postgres"), WithCode("not_found")),
+			expected: &Error{
+				Integration: "postgres",
+				Code:        "not_found",
+				Message:     "no rows in result set",
+				Validations: []Validation{},
+				Cause:       cause,
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		actual := tc.input
+
+		assert.Equal(t, tc.expected, actual)
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	cause := errors.New("no rows in result set")
+	child := errors.New("connection reset")
+
+	err := NewFromError(cause)
+	err.WithChildren(child)
+
+	assert.Equal(t, []error{cause, child}, err.Unwrap())
+	assert.Empty(t, New("This is a simple text example").Unwrap())
+}
+
+func TestError_Is(t *testing.T) {
+	ErrNotFound := New("Resource not found", WithCode("not_found"))
+	ErrNoRows := errors.New("no rows in result set")
+
+	// Build a tree of errors, with the sentinels deep in the tree.
+	leaf := NewFromError(ErrNoRows, WithIntegration("postgres"), WithCode("not_found"))
+	middle := New("Failed to get user")
+	middle.WithChildren(errors.New("cache miss"), fmt.Errorf("wrapped: %w", leaf))
+	root := New("Failed to handle request")
+	root.WithChildren(middle)
+
+	testcases := []struct {
+		target   error
+		expected bool
+	}{
+		{
+			target:   ErrNoRows,
+			expected: true,
+		},
+		{
+			target:   ErrNotFound,
+			expected: true,
+		},
+		{
+			target:   leaf,
+			expected: true,
+		},
+		{
+			target:   New("Conflict", WithCode("conflict")),
+			expected: false,
+		},
+		{
+			target:   New("Failed to get user"),
+			expected: false,
+		},
+		{
+			target:   fs.ErrNotExist,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, errors.Is(root, tc.target))
+	}
+}
+
+func TestError_As(t *testing.T) {
+	cause := &fs.PathError{Op: "open", Path: "/run/secrets/db", Err: fs.ErrNotExist}
+
+	leaf := NewFromError(cause, WithIntegration("vault"))
+	root := New("Failed to handle request")
+	root.WithChildren(errors.New("cache miss"), leaf)
+
+	var pathErr *fs.PathError
+	assert.True(t, errors.As(root, &pathErr))
+	assert.Equal(t, cause, pathErr)
+	assert.True(t, errors.Is(root, fs.ErrNotExist))
+
+	var stack *Error
+	assert.True(t, errors.As(error(root), &stack))
+	assert.Equal(t, root, stack)
+}
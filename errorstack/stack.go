@@ -0,0 +1,115 @@
+package errorstack
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+)
+
+/*
+maxStackDepth is the maximum number of frames captured for an error.
+*/
+const maxStackDepth = 32
+
+/*
+captureStack indicates if the call stack shall be captured when creating errors.
+*/
+var captureStack atomic.Bool
+
+/*
+SetStackCapture enables or disables the capture of the call stack when creating
+errors with New and NewFromError. It is disabled by default. Only the program
+counters are captured, which is cheap. They are symbolized only when the stack is
+actually used, such as with StackTrace or when formatting the error with "%+v".
+
+Stacks are automatically added as "exception.stacktrace" to the exception event
+of a span when recording an error with trace.Span.RecordError.
+*/
+func SetStackCapture(enabled bool) {
+	captureStack.Store(enabled)
+}
+
+/*
+callers returns the program counters of the caller of the function calling it,
+if the capture of the call stack is enabled.
+*/
+func callers() []uintptr {
+	if !captureStack.Load() {
+		return nil
+	}
+
+	var pcs [maxStackDepth]uintptr
+
+	// Skip runtime.Callers, callers, and the function creating the error.
+	n := runtime.Callers(3, pcs[:])
+	return pcs[:n:n]
+}
+
+/*
+StackTrace returns the frames of the call stack captured when the error has been
+created, starting with the caller of New or NewFromError. Returns nil if the call
+stack has not been captured. See SetStackCapture.
+*/
+func (err *Error) StackTrace() []runtime.Frame {
+	if len(err.stack) == 0 {
+		return nil
+	}
+
+	var trace []runtime.Frame
+	frames := runtime.CallersFrames(err.stack)
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, frame)
+		if !more {
+			break
+		}
+	}
+
+	return trace
+}
+
+/*
+Format implements fmt.Formatter. The verbs "%s" and "%v" print the same as Error,
+and "%q" prints it quoted. The verb "%+v" also prints the call stack captured when
+the error has been created, if any:
+
+	vault: Failed to read secret.
+
+	Stack trace:
+	    main.readSecret
+	        /app/main.go:42
+	    main.main
+	        /app/main.go:12
+*/
+func (err *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, err.Error())
+		if s.Flag('+') {
+			err.formatStack(s)
+		}
+
+	case 's':
+		io.WriteString(s, err.Error())
+
+	case 'q':
+		fmt.Fprintf(s, "%q", err.Error())
+	}
+}
+
+/*
+formatStack writes the call stack captured when the error has been created, if
+any.
+*/
+func (err *Error) formatStack(w io.Writer) {
+	trace := err.StackTrace()
+	if len(trace) == 0 {
+		return
+	}
+
+	io.WriteString(w, "\n\nStack trace:\n")
+	for _, frame := range trace {
+		fmt.Fprintf(w, "    %s\n        %s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+}
@@ -0,0 +1,49 @@
+package errorstack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_StackTrace(t *testing.T) {
+	assert.Nil(t, New("Stack is not captured by default").StackTrace())
+
+	SetStackCapture(true)
+	defer SetStackCapture(false)
+
+	testcases := []*Error{
+		New("This is a simple text example"),
+		NewFromError(errors.New("no rows in result set")),
+	}
+
+	for _, tc := range testcases {
+		trace := tc.StackTrace()
+
+		assert.NotEmpty(t, trace)
+		assert.Equal(t, "go.nunchi.studio/helix/errorstack.TestError_StackTrace", trace[0].Function)
+		assert.True(t, strings.HasSuffix(trace[0].File, "stack_test.go"))
+	}
+}
+
+func TestError_Format(t *testing.T) {
+	SetStackCapture(true)
+	defer SetStackCapture(false)
+
+	err := New("This is a text example with integration", WithIntegration("vault"))
+
+	assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+	assert.Equal(t, err.Error(), fmt.Sprintf("%s", err))
+	assert.Equal(t, `"vault: This is a text example with integration."`, fmt.Sprintf("%q", err))
+
+	verbose := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(verbose, err.Error()+"\n\nStack trace:\n"))
+	assert.Contains(t, verbose, "    go.nunchi.studio/helix/errorstack.TestError_Format\n")
+	assert.Contains(t, verbose, "stack_test.go:")
+
+	SetStackCapture(false)
+	assert.Equal(t, err.Error(), fmt.Sprintf("%+v", New("This is a text example with integration", WithIntegration("vault"))))
+}
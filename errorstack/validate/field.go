@@ -0,0 +1,281 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Field is a value to validate with rules. Rules can be chained, and are evaluated
+in order. Rules other than Required are skipped if the value is empty, so optional
+values are only validated when set. Once a rule fails, the next ones are skipped.
+*/
+type Field struct {
+
+	// name is the name of the field, used in messages.
+	name string
+
+	// path is the path of the field.
+	path []string
+
+	// value is the value of the field.
+	value reflect.Value
+
+	// failed indicates if a rule has already failed for the field.
+	failed bool
+
+	// validations holds the validation failures encountered, shared with the
+	// validator of the field.
+	validations *[]errorstack.Validation
+}
+
+/*
+Required ensures the value is set: not nil, and not empty for strings, slices,
+arrays, and maps.
+*/
+func (f *Field) Required() *Field {
+	if f.failed || !isEmpty(f.value) {
+		return f
+	}
+
+	switch f.value.Kind() {
+	case reflect.Invalid, reflect.Pointer, reflect.Interface, reflect.Func, reflect.Chan:
+		return f.fail("required", nil, "%s must be set and not be nil", f.name)
+	}
+
+	return f.fail("required", nil, "%s must be set and not be empty", f.name)
+}
+
+/*
+Min ensures the value is greater than or equal to the minimum passed for numbers,
+is at least as long as the minimum for strings (in characters), and contains at
+least the minimum of items for slices, arrays, and maps.
+*/
+func (f *Field) Min(min float64) *Field {
+	if f.skip() {
+		return f
+	}
+
+	v := indirect(f.value)
+	params := map[string]any{"min": min}
+	switch kind := v.Kind(); {
+	case kind == reflect.String:
+		if float64(utf8.RuneCountInString(v.String())) < min {
+			return f.fail("min_length", params, "%s must be at least %s characters long", f.name, format(min))
+		}
+
+	case kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map:
+		if float64(v.Len()) < min {
+			return f.fail("min_items", params, "%s must contain at least %s items", f.name, format(min))
+		}
+
+	default:
+		if number(v, f.name) < min {
+			return f.fail("minimum", params, "%s must be greater than or equal to %s", f.name, format(min))
+		}
+	}
+
+	return f
+}
+
+/*
+Max ensures the value is less than or equal to the maximum passed for numbers,
+is at most as long as the maximum for strings (in characters), and contains at
+most the maximum of items for slices, arrays, and maps.
+*/
+func (f *Field) Max(max float64) *Field {
+	if f.skip() {
+		return f
+	}
+
+	v := indirect(f.value)
+	params := map[string]any{"max": max}
+	switch kind := v.Kind(); {
+	case kind == reflect.String:
+		if float64(utf8.RuneCountInString(v.String())) > max {
+			return f.fail("max_length", params, "%s must be at most %s characters long", f.name, format(max))
+		}
+
+	case kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map:
+		if float64(v.Len()) > max {
+			return f.fail("max_items", params, "%s must contain at most %s items", f.name, format(max))
+		}
+
+	default:
+		if number(v, f.name) > max {
+			return f.fail("maximum", params, "%s must be less than or equal to %s", f.name, format(max))
+		}
+	}
+
+	return f
+}
+
+/*
+Match ensures the value matches the regular expression passed. The value must be
+a string. It panics if the regular expression can not be compiled.
+*/
+func (f *Field) Match(pattern string) *Field {
+	if f.skip() {
+		return f
+	}
+
+	v := indirect(f.value)
+	if v.Kind() != reflect.String {
+		panic(fmt.Sprintf("validate: field %q must be a string to match a pattern", f.name))
+	}
+
+	if !compile(pattern).MatchString(v.String()) {
+		return f.fail("pattern", map[string]any{"pattern": pattern}, "%s must match pattern %s", f.name, pattern)
+	}
+
+	return f
+}
+
+/*
+OneOf ensures the value is one of the values passed. Values are compared with
+their default format, so a value of a custom string type can be compared with
+strings.
+*/
+func (f *Field) OneOf(values ...any) *Field {
+	if f.skip() {
+		return f
+	}
+
+	actual := fmt.Sprint(indirect(f.value).Interface())
+	allowed := make([]string, len(values))
+	for i, value := range values {
+		allowed[i] = fmt.Sprint(value)
+		if allowed[i] == actual {
+			return f
+		}
+	}
+
+	list := strings.Join(allowed, ", ")
+	return f.fail("enum", map[string]any{"values": list}, "%s must be one of %s", f.name, list)
+}
+
+/*
+Check adds a validation failure with the code and message passed if the value is
+not valid. This allows to apply custom rules. Unlike other rules, it is evaluated
+even if the value is empty.
+
+Example:
+
+	v.Field("Subfolder", cfg.Subfolder).Check(strings.HasSuffix(cfg.Subfolder, "/"), "trailing_slash", "Subfolder must end with a trailing slash")
+*/
+func (f *Field) Check(valid bool, code string, message string) *Field {
+	if f.failed || valid {
+		return f
+	}
+
+	return f.fail(code, nil, "%s", message)
+}
+
+/*
+Valid indicates if all the rules applied to the field passed.
+*/
+func (f *Field) Valid() bool {
+	return !f.failed
+}
+
+/*
+skip indicates if a rule shall be skipped, either because a previous one failed
+or because the value is empty.
+*/
+func (f *Field) skip() bool {
+	return f.failed || isEmpty(f.value)
+}
+
+/*
+fail adds a validation failure for the field.
+*/
+func (f *Field) fail(code string, params map[string]any, format string, args ...any) *Field {
+	f.failed = true
+	*f.validations = append(*f.validations, errorstack.Validation{
+		Message: fmt.Sprintf(format, args...),
+		Code:    code,
+		Params:  params,
+		Path:    f.path,
+	})
+
+	return f
+}
+
+/*
+indirect returns the value pointed to by the value passed, if it is a non-nil
+pointer or interface.
+*/
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	return v
+}
+
+/*
+isEmpty indicates if the value passed is nil, or is an empty string, slice, array,
+or map. Numbers and booleans are never empty, so their zero value is validated.
+*/
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Pointer, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() == 0
+	}
+
+	return false
+}
+
+/*
+number returns the value passed as a float. It panics if the value is not a
+number, since a rule can not be applied to the field.
+*/
+func number(v reflect.Value, name string) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	case v.CanFloat():
+		return v.Float()
+	}
+
+	panic(fmt.Sprintf("validate: field %q must be a number, string, slice, array, or map to be compared", name))
+}
+
+/*
+format returns the number passed formatted with the minimal number of digits.
+*/
+func format(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+/*
+patterns caches the regular expressions compiled, by pattern.
+*/
+var patterns sync.Map
+
+/*
+compile returns the regular expression of the pattern passed, compiled once.
+*/
+func compile(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+
+	return re
+}
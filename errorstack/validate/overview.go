@@ -0,0 +1,26 @@
+/*
+Package validate exposes a single mechanism to validate values and return the
+failures encountered as errorstack validations, with their path, code, and params.
+It is used by integrations to validate their configuration, and can be used by
+services to validate request payloads.
+
+Values can be validated with struct tags:
+
+	type User struct {
+	  Name  string   `json:"name" validate:"required,min=3,max=64"`
+	  Email string   `json:"email" validate:"required,regex=^[^@]+@[^@]+$"`
+	  Role  string   `json:"role" validate:"oneof=admin member"`
+	  Tags  []string `json:"tags" validate:"max=10"`
+	}
+
+	validations := validate.Struct(user, validate.WithPath("request", "body"))
+
+Or with a fluent builder:
+
+	v := validate.New("Config")
+	v.Field("Address", cfg.Address).Required()
+	v.Nested("Worker").Field("Concurrency", cfg.Worker.Concurrency).Min(1).Max(64)
+
+	validations := v.Validations()
+*/
+package validate
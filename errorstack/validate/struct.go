@@ -0,0 +1,174 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Struct validates the struct passed, or the struct pointed to, with the rules set
+in the "validate" tag of its fields. Nested structs, and slices and arrays of
+structs, are validated recursively. Paths and messages rely on the JSON names of
+the fields, unless WithFieldNames is passed. Returns nil if the value passed is
+not a struct.
+
+Supported rules, separated by commas:
+
+  - "required": see Field.Required
+  - "min=<n>": see Field.Min
+  - "max=<n>": see Field.Max
+  - "oneof=<a> <b>": see Field.OneOf, with values separated by spaces
+  - "regex=<pattern>": see Field.Match, with a pattern that can not contain commas
+  - "-": the field and its nested fields are not validated
+
+It panics if a rule is not supported or not valid, since this is a programming
+error.
+*/
+func Struct(value any, opts ...With) []errorstack.Validation {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	v := &Validator{
+		path:        o.path,
+		validations: new([]errorstack.Validation),
+	}
+
+	validateStruct(v, reflect.ValueOf(value), o)
+	return v.Validations()
+}
+
+/*
+validateStruct validates the fields of the struct passed with their tags.
+*/
+func validateStruct(v *Validator, rv reflect.Value, o *options) {
+	rv = indirect(rv)
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		name, embedded := fieldName(field, o)
+		if embedded {
+			validateStruct(v, rv.Field(i), o)
+			continue
+		}
+
+		f := v.Field(name, rv.Field(i).Interface())
+		if tag != "" {
+			applyRules(f, tag)
+		}
+
+		validateNested(v.Nested(name), rv.Field(i), o)
+	}
+}
+
+/*
+validateNested validates the value passed if it is a struct, or each of its
+elements if it is a slice or an array of structs.
+*/
+func validateNested(v *Validator, rv reflect.Value, o *options) {
+	rv = indirect(rv)
+	switch rv.Kind() {
+	case reflect.Struct:
+		validateStruct(v, rv, o)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			elem := indirect(rv.Index(i))
+			if elem.Kind() == reflect.Struct {
+				validateStruct(v.Nested(strconv.Itoa(i)), elem, o)
+			}
+		}
+	}
+}
+
+/*
+fieldName returns the name of the field used in paths and messages. It also
+informs if the field is embedded and shall be flattened, just like encoding/json
+does.
+*/
+func fieldName(field reflect.StructField, o *options) (string, bool) {
+	if o.fieldNames {
+		return field.Name, field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return field.Name, false
+	}
+
+	if name == "" {
+		return field.Name, field.Anonymous && indirectType(field.Type).Kind() == reflect.Struct
+	}
+
+	return name, false
+}
+
+/*
+indirectType returns the type pointed to by the type passed, if it is a pointer.
+*/
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+/*
+applyRules applies the rules of the tag passed to the field.
+*/
+func applyRules(f *Field, tag string) {
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			f.Required()
+		case "min":
+			f.Min(parseNumber(f, key, arg))
+		case "max":
+			f.Max(parseNumber(f, key, arg))
+		case "oneof":
+			var values []any
+			for _, value := range strings.Fields(arg) {
+				values = append(values, value)
+			}
+
+			f.OneOf(values...)
+		case "regex":
+			f.Match(arg)
+		default:
+			panic(fmt.Sprintf("validate: rule %q of field %q is not supported", rule, f.name))
+		}
+	}
+}
+
+/*
+parseNumber parses the number argument of a rule. It panics if the argument is
+not a number.
+*/
+func parseNumber(f *Field, key string, arg string) float64 {
+	n, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: rule %q of field %q must have a number argument", key, f.name))
+	}
+
+	return n
+}
@@ -0,0 +1,202 @@
+package validate
+
+import (
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Payload struct {
+	Name     string    `json:"name" validate:"required,min=3,max=8"`
+	Email    string    `json:"email,omitempty" validate:"regex=^[^@]+@[^@]+$"`
+	Role     string    `json:"role" validate:"oneof=admin member"`
+	Age      int       `json:"age" validate:"min=18"`
+	Tags     []string  `json:"tags" validate:"max=2"`
+	Address  *Address  `json:"address" validate:"required"`
+	Contacts []Contact `json:"contacts"`
+	Internal string    `json:"-" validate:"required"`
+	Ignored  *Payload  `json:"ignored" validate:"-"`
+}
+
+type Address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type Contact struct {
+	Phone string `json:"phone" validate:"required"`
+}
+
+func TestStruct(t *testing.T) {
+	testcases := []struct {
+		input    any
+		opts     []With
+		expected []errorstack.Validation
+	}{
+		{
+			input: Payload{
+				Name:     "John",
+				Email:    "john@example.com",
+				Role:     "admin",
+				Age:      18,
+				Address:  &Address{City: "Paris"},
+				Contacts: []Contact{{Phone: "0102030405"}},
+				Internal: "value",
+				Ignored:  &Payload{},
+			},
+			expected: []errorstack.Validation{},
+		},
+		{
+			input: &Payload{
+				Name:     "Jo",
+				Email:    "john",
+				Role:     "owner",
+				Age:      12,
+				Tags:     []string{"a", "b", "c"},
+				Contacts: []Contact{{Phone: "0102030405"}, {}},
+			},
+			opts: []With{WithPath("request", "body")},
+			expected: []errorstack.Validation{
+				{
+					Message: "name must be at least 3 characters long",
+					Code:    "min_length",
+					Params:  map[string]any{"min": float64(3)},
+					Path:    []string{"request", "body", "name"},
+				},
+				{
+					Message: "email must match pattern ^[^@]+@[^@]+$",
+					Code:    "pattern",
+					Params:  map[string]any{"pattern": "^[^@]+@[^@]+$"},
+					Path:    []string{"request", "body", "email"},
+				},
+				{
+					Message: "role must be one of admin, member",
+					Code:    "enum",
+					Params:  map[string]any{"values": "admin, member"},
+					Path:    []string{"request", "body", "role"},
+				},
+				{
+					Message: "age must be greater than or equal to 18",
+					Code:    "minimum",
+					Params:  map[string]any{"min": float64(18)},
+					Path:    []string{"request", "body", "age"},
+				},
+				{
+					Message: "tags must contain at most 2 items",
+					Code:    "max_items",
+					Params:  map[string]any{"max": float64(2)},
+					Path:    []string{"request", "body", "tags"},
+				},
+				{
+					Message: "address must be set and not be nil",
+					Code:    "required",
+					Path:    []string{"request", "body", "address"},
+				},
+				{
+					Message: "phone must be set and not be empty",
+					Code:    "required",
+					Path:    []string{"request", "body", "contacts", "1", "phone"},
+				},
+				{
+					Message: "Internal must be set and not be empty",
+					Code:    "required",
+					Path:    []string{"request", "body", "Internal"},
+				},
+			},
+		},
+		{
+			input: Payload{
+				Name:     "Johnathan Doe",
+				Age:      18,
+				Address:  &Address{},
+				Internal: "value",
+			},
+			opts: []With{WithPath("Config"), WithFieldNames()},
+			expected: []errorstack.Validation{
+				{
+					Message: "Name must be at most 8 characters long",
+					Code:    "max_length",
+					Params:  map[string]any{"max": float64(8)},
+					Path:    []string{"Config", "Name"},
+				},
+				{
+					Message: "City must be set and not be empty",
+					Code:    "required",
+					Path:    []string{"Config", "Address", "City"},
+				},
+			},
+		},
+		{
+			input:    "not a struct",
+			expected: []errorstack.Validation{},
+		},
+	}
+
+	for _, tc := range testcases {
+		actual := Struct(tc.input, tc.opts...)
+
+		assert.ElementsMatch(t, tc.expected, actual)
+	}
+}
+
+func TestStruct_Panics(t *testing.T) {
+	assert.Panics(t, func() {
+		Struct(struct {
+			Name string `validate:"unknown"`
+		}{})
+	})
+
+	assert.Panics(t, func() {
+		Struct(struct {
+			Enabled bool `validate:"min=1"`
+		}{})
+	})
+}
+
+func TestValidator(t *testing.T) {
+	type Server struct {
+		Address string
+	}
+
+	v := New("Config")
+	v.Field("Driver", nil).Required()
+	v.Field("Subfolder", "my/subfolder").Check(false, "trailing_slash", "Subfolder must end with a trailing slash")
+	v.Field("Timeout", 0).Required().Min(1).Max(60)
+	v.Field("Optional", "").Min(3).Match("^[a-z]+$")
+	v.Nested("Worker").Field("TaskQueue", "").Required().Min(3)
+	v.Each("Servers", []Server{{Address: "localhost"}, {}}, func(v *Validator, elem any) {
+		v.Field("Address", elem.(Server).Address).Required()
+	})
+
+	expected := []errorstack.Validation{
+		{
+			Message: "Driver must be set and not be nil",
+			Code:    "required",
+			Path:    []string{"Config", "Driver"},
+		},
+		{
+			Message: "Subfolder must end with a trailing slash",
+			Code:    "trailing_slash",
+			Path:    []string{"Config", "Subfolder"},
+		},
+		{
+			Message: "Timeout must be greater than or equal to 1",
+			Code:    "minimum",
+			Params:  map[string]any{"min": float64(1)},
+			Path:    []string{"Config", "Timeout"},
+		},
+		{
+			Message: "TaskQueue must be set and not be empty",
+			Code:    "required",
+			Path:    []string{"Config", "Worker", "TaskQueue"},
+		},
+		{
+			Message: "Address must be set and not be empty",
+			Code:    "required",
+			Path:    []string{"Config", "Servers", "1", "Address"},
+		},
+	}
+
+	assert.Equal(t, expected, v.Validations())
+}
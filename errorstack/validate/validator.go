@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Validator accumulates the validation failures encountered when validating values
+at a given path. Validators returned by Nested share the same failures as their
+parent.
+*/
+type Validator struct {
+
+	// path is the path of the values validated.
+	path []string
+
+	// validations holds the validation failures encountered, shared across nested
+	// validators.
+	validations *[]errorstack.Validation
+}
+
+/*
+New returns a new validator for values at the path passed.
+
+Example:
+
+	v := validate.New("Config")
+*/
+func New(path ...string) *Validator {
+	return &Validator{
+		path:        path,
+		validations: new([]errorstack.Validation),
+	}
+}
+
+/*
+Nested returns a validator for values nested at the path passed, relative to the
+current one. Validation failures are shared with the current validator.
+
+Example:
+
+	v.Nested("Worker").Field("TaskQueue", cfg.Worker.TaskQueue).Required()
+*/
+func (v *Validator) Nested(path ...string) *Validator {
+	return &Validator{
+		path:        concat(v.path, path...),
+		validations: v.validations,
+	}
+}
+
+/*
+Field returns a field to validate with rules, given its name and value.
+*/
+func (v *Validator) Field(name string, value any) *Field {
+	return &Field{
+		name:        name,
+		path:        concat(v.path, name),
+		value:       reflect.ValueOf(value),
+		validations: v.validations,
+	}
+}
+
+/*
+Each validates each element of the slice or array passed with the function, using
+a validator nested at the index of the element. It does nothing if the value is
+not a slice nor an array.
+
+Example:
+
+	v.Each("Servers", cfg.Servers, func(v *validate.Validator, server any) {
+	  v.Field("Address", server.(Server).Address).Required()
+	})
+*/
+func (v *Validator) Each(name string, value any, fn func(v *Validator, elem any)) {
+	rv := indirect(reflect.ValueOf(value))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		fn(v.Nested(name, strconv.Itoa(i)), rv.Index(i).Interface())
+	}
+}
+
+/*
+Struct validates the struct passed with its tags, just like the package-level
+Struct function, at the path of the validator.
+*/
+func (v *Validator) Struct(value any, opts ...With) {
+	opts = append([]With{WithPath(v.path...)}, opts...)
+	*v.validations = append(*v.validations, Struct(value, opts...)...)
+}
+
+/*
+Validations returns the validation failures encountered by the validator and its
+nested ones.
+*/
+func (v *Validator) Validations() []errorstack.Validation {
+	return *v.validations
+}
+
+/*
+concat returns a new path made of the path and elements passed, without modifying
+the path.
+*/
+func concat(path []string, elems ...string) []string {
+	return append(slices.Clip(path), elems...)
+}
@@ -0,0 +1,38 @@
+package validate
+
+/*
+options holds the options to validate structs with Struct.
+*/
+type options struct {
+	path       []string
+	fieldNames bool
+}
+
+/*
+With allows to set optional values when validating structs with Struct.
+*/
+type With func(*options)
+
+/*
+WithPath sets the path the struct validated is at, prepended to the path of every
+validation failure.
+
+Example:
+
+	validate.Struct(payload, validate.WithPath("request", "body"))
+*/
+func WithPath(path ...string) With {
+	return func(opts *options) {
+		opts.path = append(opts.path, path...)
+	}
+}
+
+/*
+WithFieldNames uses the Go names of the fields in paths and messages, instead of
+their JSON names. This is used by integrations to validate their configuration.
+*/
+func WithFieldNames() With {
+	return func(opts *options) {
+		opts.fieldNames = true
+	}
+}
@@ -13,3 +13,12 @@ func WithIntegration(inte string) With {
 		err.Integration = inte
 	}
 }
+
+/*
+WithCode sets the machine-readable code of the error.
+*/
+func WithCode(code Code) With {
+	return func(err *Error) {
+		err.Code = code
+	}
+}
@@ -24,16 +24,25 @@ func EventFromContext(ctx context.Context) (Event, bool) {
 
 /*
 ContextWithEvent returns a copy of the context passed with the Event associated
-to it.
+to it. If the Event is not valid against its schema and the enforcement policy is
+EnforcementDrop, the context passed is returned as-is. An Event with no name is
+not enforced, since it is still being built, such as by integrations populating
+its fields from an HTTP request before handlers set its name. It is enforced once
+added again with a name.
 */
 func ContextWithEvent(ctx context.Context, e Event) context.Context {
+	if e.Name != "" && !Enforce(e) {
+		return ctx
+	}
+
 	return context.WithValue(ctx, contextkey.Event, e)
 }
 
 /*
 eventFromBaggage returns the Event found in the Baggage passed, if any. Returns
 true if an Event has been found, false otherwise. An event is considered found
-if — and only if — the name is not empty.
+if — and only if — the name is not empty and it is not dropped by the enforcement
+policy.
 
 Example:
 
@@ -67,7 +76,7 @@ Will produce:
 */
 func eventFromBaggage(b baggage.Baggage) (Event, bool) {
 	e := extractEventFromBaggage(b)
-	if e.Name == "" {
+	if e.Name == "" || !Enforce(e) {
 		return e, false
 	}
 
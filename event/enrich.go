@@ -0,0 +1,153 @@
+package event
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Enricher is the interface a stage of the enrichment pipeline must implement. An
+Enricher populates some fields of an Event from the ones already set, such as the
+Location from the IP address. An Enricher must only populate empty fields, so it
+never overrides values set by the caller or by a previous service.
+*/
+type Enricher interface {
+	Enrich(ctx context.Context, e *Event) error
+}
+
+/*
+Ensure EnricherFunc complies to the Enricher type.
+*/
+var _ Enricher = (EnricherFunc)(nil)
+
+/*
+EnricherFunc allows to use an ordinary function as an Enricher.
+*/
+type EnricherFunc func(ctx context.Context, e *Event) error
+
+/*
+Enrich calls f(ctx, e).
+*/
+func (f EnricherFunc) Enrich(ctx context.Context, e *Event) error {
+	return f(ctx, e)
+}
+
+/*
+chain is an Enricher calling a list of enrichers in order.
+*/
+type chain []Enricher
+
+/*
+Chain returns an Enricher calling the enrichers passed in order, so each one can
+rely on the fields populated by the previous ones. An Enricher returning an error
+doesn't stop the chain: errors are returned together once all enrichers have been
+called.
+*/
+func Chain(enrichers ...Enricher) Enricher {
+	return chain(enrichers)
+}
+
+/*
+Enrich calls every Enricher of the chain in order.
+*/
+func (c chain) Enrich(ctx context.Context, e *Event) error {
+	stack := errorstack.New("Failed to enrich event")
+	for _, enricher := range c {
+		if enricher == nil {
+			continue
+		}
+
+		if err := enricher.Enrich(ctx, e); err != nil {
+			stack.WithChildren(err)
+		}
+	}
+
+	if stack.HasChildren() {
+		return stack
+	}
+
+	return nil
+}
+
+/*
+enrichers is the chain of enrichers currently applied.
+*/
+var enrichers atomic.Value
+
+/*
+SetEnrichers sets the enrichers applied by Enrich, in order. Integrations call
+Enrich when an Event enters a service, such as from an HTTP request, a NATS message,
+or a Temporal activity. Events passed to Temporal workflows are enriched when the
+workflow is started, since workflows must be deterministic. The Event enriched is
+the one added to the context, and is therefore propagated to other services. Use
+the Deny list of trace.BaggagePolicy to not propagate some of its fields.
+
+Example:
+
+	geo, err := geoip.Open("/usr/share/GeoIP/GeoLite2-City.mmdb")
+	if err != nil {
+	  return err
+	}
+
+	event.SetEnrichers(event.UserAgentEnricher{}, geo)
+*/
+func SetEnrichers(list ...Enricher) {
+	enrichers.Store(Chain(list...))
+}
+
+/*
+Enrich returns the Event passed enriched by the enrichers set with SetEnrichers.
+Enrichment is best-effort: if an Enricher fails, the error is passed to the handler
+set with SetErrorHandler and the Event is returned with the fields populated so
+far.
+*/
+func Enrich(ctx context.Context, e Event) Event {
+	enricher, _ := enrichers.Load().(Enricher)
+	if enricher == nil {
+		return e
+	}
+
+	if err := enricher.Enrich(ctx, &e); err != nil {
+		handleError(err)
+	}
+
+	return e
+}
+
+/*
+Ensure UserAgentEnricher complies to the Enricher type.
+*/
+var _ Enricher = UserAgentEnricher{}
+
+/*
+UserAgentEnricher is an Enricher populating the App, Device, and OS of an Event
+from its user agent, if they are empty. This is useful for events built by clients
+only forwarding the user agent. The zero value is ready to use.
+*/
+type UserAgentEnricher struct{}
+
+/*
+Enrich parses the user agent of the Event passed.
+*/
+func (UserAgentEnricher) Enrich(ctx context.Context, e *Event) error {
+	if e.UserAgent == "" {
+		return nil
+	}
+
+	app, device, os := parseUserAgent(e.UserAgent)
+	if e.App == (App{}) {
+		e.App = app
+	}
+
+	if e.Device == (Device{}) {
+		e.Device = device
+	}
+
+	if e.OS == (OS{}) {
+		e.OS = os
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	enricher := Chain(
+		EnricherFunc(func(ctx context.Context, e *Event) error {
+			calls = append(calls, "first")
+			e.Timezone = "Europe/Paris"
+			return errors.New("unavailable")
+		}),
+		nil,
+		EnricherFunc(func(ctx context.Context, e *Event) error {
+			calls = append(calls, "second")
+			e.Locale = e.Timezone
+			return nil
+		}),
+	)
+
+	e := Event{}
+	err := enricher.Enrich(context.Background(), &e)
+
+	assert.ErrorContains(t, err, "Failed to enrich event")
+	assert.ErrorContains(t, err, "unavailable")
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Equal(t, Event{Timezone: "Europe/Paris", Locale: "Europe/Paris"}, e)
+}
+
+func TestEnrich(t *testing.T) {
+	var reported []error
+	SetErrorHandler(func(err error) {
+		reported = append(reported, err)
+	})
+
+	defer SetErrorHandler(nil)
+	defer SetEnrichers()
+
+	input := Event{
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7; rv:121.0) Gecko/20100101 Firefox/121.0",
+		App: App{
+			Name: "helix",
+		},
+	}
+
+	// No enricher is set.
+	assert.Equal(t, input, Enrich(context.Background(), input))
+
+	SetEnrichers(UserAgentEnricher{}, EnricherFunc(func(ctx context.Context, e *Event) error {
+		return errors.New("unavailable")
+	}))
+
+	expected := input
+	expected.Device = Device{Manufacturer: "Apple", Model: "Mac", Type: "desktop"}
+	expected.OS = OS{Name: "macOS", Version: "10.15.7"}
+
+	assert.Equal(t, expected, Enrich(context.Background(), input))
+	assert.Len(t, reported, 1)
+}
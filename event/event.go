@@ -61,38 +61,67 @@ type Event struct {
 injectEventToFlatMap injects values found in an Event object to a flat map
 representation of an Event. Top-level keys are handled here, while objects
 are handled in their own functions for better clarity and maintainability.
+
+Empty strings and zero values are omitted, since they are restored as-is when
+extracting the Event. Keys and values of Meta, Params, and flags are always
+injected, even if empty, and their keys are escaped with escapeKey so they can
+contain any character.
 */
 func injectEventToFlatMap(e Event, flatten map[string]string) {
 	if flatten == nil {
 		flatten = make(map[string]string)
 	}
 
-	flatten["event.id"] = e.ID
-	flatten["event.name"] = e.Name
+	if e.ID != "" {
+		flatten["event.id"] = e.ID
+	}
 
-	if e.Meta != nil {
-		for k, v := range e.Meta {
-			flatten[fmt.Sprintf("event.meta.%s", k)] = v
-		}
+	if e.Name != "" {
+		flatten["event.name"] = e.Name
 	}
 
-	if e.Params != nil {
-		for k, v := range e.Params {
-			split := strings.Split(k, ".")
-			for i, s := range v {
-				flatten[fmt.Sprintf("event.params.%s[%d]", split[0], i)] = s
-			}
+	for k, v := range e.Meta {
+		flatten[fmt.Sprintf("event.meta.%s", escapeKey(k))] = v
+	}
+
+	for k, v := range e.Params {
+		for i, s := range v {
+			flatten[fmt.Sprintf("event.params.%s[%d]", escapeKey(k), i)] = s
 		}
 	}
 
-	flatten["event.is_anonymous"] = strconv.FormatBool(e.IsAnonymous)
-	flatten["event.user_id"] = e.UserID
-	flatten["event.group_id"] = e.GroupID
-	flatten["event.tenant_id"] = e.TenantID
-	flatten["event.ip"] = e.IP.String()
-	flatten["event.user_agent"] = e.UserAgent
-	flatten["event.locale"] = e.Locale
-	flatten["event.timezone"] = e.Timezone
+	if e.IsAnonymous {
+		flatten["event.is_anonymous"] = strconv.FormatBool(e.IsAnonymous)
+	}
+
+	if e.UserID != "" {
+		flatten["event.user_id"] = e.UserID
+	}
+
+	if e.GroupID != "" {
+		flatten["event.group_id"] = e.GroupID
+	}
+
+	if e.TenantID != "" {
+		flatten["event.tenant_id"] = e.TenantID
+	}
+
+	if e.IP != nil {
+		flatten["event.ip"] = e.IP.String()
+	}
+
+	if e.UserAgent != "" {
+		flatten["event.user_agent"] = e.UserAgent
+	}
+
+	if e.Locale != "" {
+		flatten["event.locale"] = e.Locale
+	}
+
+	if e.Timezone != "" {
+		flatten["event.timezone"] = e.Timezone
+	}
+
 	if !e.Timestamp.IsZero() {
 		flatten["event.timestamp"] = e.Timestamp.Format(time.RFC3339Nano)
 	}
@@ -109,12 +138,6 @@ func injectEventToFlatMap(e Event, flatten map[string]string) {
 	injectEventReferrerToFlatMap(e.Referrer, flatten)
 	injectEventScreenToFlatMap(e.Screen, flatten)
 	injectEventSubscriptionsToFlatMap(e.Subscriptions, flatten)
-
-	for k, v := range flatten {
-		if v == "" || v == "false" || v == "0" || v == "0E+00" || v == "0.000000" || v == "<nil>" {
-			delete(flatten, k)
-		}
-	}
 }
 
 /*
@@ -136,7 +159,7 @@ func extractEventFromBaggage(b baggage.Baggage) Event {
 				e.Meta = make(map[string]string)
 			}
 
-			e.Meta[strings.TrimPrefix(m.Key(), "event.meta.")] = m.Value()
+			e.Meta[unescapeKey(strings.TrimPrefix(m.Key(), "event.meta."))] = m.Value()
 			continue
 		}
 
@@ -145,14 +168,30 @@ func extractEventFromBaggage(b baggage.Baggage) Event {
 				e.Params = make(url.Values)
 			}
 
-			key := strings.TrimPrefix(m.Key(), "event.params.")
-			split := strings.Split(key, ".")
+			// Values are set at their index, since the Baggage members are not ordered.
+			// An invalid index would otherwise override the first value.
+			key, index, _ := strings.Cut(strings.TrimPrefix(m.Key(), "event.params."), ".")
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 || i >= maxBaggageMembers {
+				continue
+			}
 
-			e.Params.Add(split[0], m.Value())
+			key = unescapeKey(key)
+			for i > len(e.Params[key])-1 {
+				e.Params[key] = append(e.Params[key], "")
+			}
+
+			e.Params[key][i] = m.Value()
 			continue
 		}
 
+		// Members of objects have at least three levels, such as "event.app.name".
+		// Skip malformed ones coming from untrusted clients.
 		split := strings.Split(m.Key(), ".")
+		if _, isObject := objects[split[1]]; isObject && len(split) < 3 {
+			continue
+		}
+
 		switch split[1] {
 		case "id":
 			e.ID = b.Member("event.id").Value()
@@ -206,3 +245,59 @@ func extractEventFromBaggage(b baggage.Baggage) Event {
 
 	return e
 }
+
+/*
+maxBaggageMembers is the maximum number of members in a Baggage. It is used to
+bound the indexes of arrays found in Baggage members' keys.
+*/
+const maxBaggageMembers = 180
+
+/*
+escapeKey escapes a key of a map so it can be part of a key of the flat map, and
+of a Baggage member's key. Characters other than letters, digits, "_", and "-"
+are percent-encoded, so dots and brackets can not be confused with the levels
+and indexes of the flat map.
+
+Example:
+
+	"utm.source"
+
+Will produce:
+
+	"utm%2Esource"
+*/
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '_' || c == '-' {
+			b.WriteByte(c)
+			continue
+		}
+
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+
+	return b.String()
+}
+
+/*
+unescapeKey reverts a key escaped with escapeKey. The key is returned as-is if
+it is not escaped properly.
+*/
+func unescapeKey(key string) string {
+	unescaped, err := url.PathUnescape(key)
+	if err != nil {
+		return key
+	}
+
+	return unescaped
+}
+
+/*
+formatFloat returns the shortest representation of a float that is parsed back
+to the exact same value.
+*/
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
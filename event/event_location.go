@@ -1,7 +1,6 @@
 package event
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -42,15 +41,15 @@ func injectEventLocationToFlatMap(location Location, flatten map[string]string)
 	}
 
 	if location.Latitude != 0 {
-		flatten["event.location.latitude"] = fmt.Sprintf("%f", location.Latitude)
+		flatten["event.location.latitude"] = formatFloat(location.Latitude)
 	}
 
 	if location.Longitude != 0 {
-		flatten["event.location.longitude"] = fmt.Sprintf("%f", location.Longitude)
+		flatten["event.location.longitude"] = formatFloat(location.Longitude)
 	}
 
 	if location.Speed != 0 {
-		flatten["event.location.speed"] = fmt.Sprintf("%f", location.Speed)
+		flatten["event.location.speed"] = formatFloat(location.Speed)
 	}
 }
 
@@ -29,9 +29,9 @@ func TestInjectEventLocationToFlatMap(t *testing.T) {
 				"event.location.city":      "location_city_test",
 				"event.location.country":   "location_country_test",
 				"event.location.region":    "location_region_test",
-				"event.location.latitude":  "45.916000",
-				"event.location.longitude": "6.133000",
-				"event.location.speed":     "50.000000",
+				"event.location.latitude":  "45.916",
+				"event.location.longitude": "6.133",
+				"event.location.speed":     "50",
 			},
 		},
 	}
@@ -97,7 +97,7 @@ func TestApplyEventLocationFromBaggageMember(t *testing.T) {
 		},
 		{
 			input: func() baggage.Member {
-				m, _ := baggage.NewMember("event.location.latitude", "45.916000")
+				m, _ := baggage.NewMember("event.location.latitude", "45.916")
 				return m
 			},
 			expected: &Event{
@@ -108,7 +108,7 @@ func TestApplyEventLocationFromBaggageMember(t *testing.T) {
 		},
 		{
 			input: func() baggage.Member {
-				m, _ := baggage.NewMember("event.location.longitude", "6.133000")
+				m, _ := baggage.NewMember("event.location.longitude", "6.133")
 				return m
 			},
 			expected: &Event{
@@ -119,7 +119,7 @@ func TestApplyEventLocationFromBaggageMember(t *testing.T) {
 		},
 		{
 			input: func() baggage.Member {
-				m, _ := baggage.NewMember("event.location.speed", "50.000000")
+				m, _ := baggage.NewMember("event.location.speed", "50")
 				return m
 			},
 			expected: &Event{
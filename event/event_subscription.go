@@ -52,14 +52,12 @@ func injectEventSubscriptionsToFlatMap(subs []Subscription, flatten map[string]s
 			flatten[fmt.Sprintf("event.subscriptions[%d].usage", i)] = sub.Usage
 		}
 
-		if sub.IncrementBy != 0 {
-			flatten[fmt.Sprintf("event.subscriptions[%d].increment_by", i)] = fmt.Sprintf("%f", sub.IncrementBy)
-		}
+		// Always inject the increment, so a subscription is still present in the
+		// flat map when all its other fields are empty.
+		flatten[fmt.Sprintf("event.subscriptions[%d].increment_by", i)] = formatFloat(sub.IncrementBy)
 
-		if sub.Flags != nil {
-			for k, v := range sub.Flags {
-				flatten[fmt.Sprintf("event.subscriptions[%d].flags.%s", i, k)] = v
-			}
+		for k, v := range sub.Flags {
+			flatten[fmt.Sprintf("event.subscriptions[%d].flags.%s", i, escapeKey(k))] = v
 		}
 	}
 }
@@ -76,7 +74,11 @@ func applyEventSubscriptionsFromBaggageMember(m baggage.Member, e *Event) {
 	// the current length of the Subscriptions slice. Since the Baggage members
 	// are not ordered, a key with index 1 may be called before one with index 0,
 	// such as "event.subscriptions[1].id" called before "event.subscriptions[0].id".
-	i, _ := strconv.Atoi(split[2])
+	i, err := strconv.Atoi(split[2])
+	if err != nil || i < 0 || i >= maxBaggageMembers || len(split) < 4 {
+		return
+	}
+
 	for i > len(e.Subscriptions)-1 {
 		e.Subscriptions = append(e.Subscriptions, Subscription{})
 	}
@@ -99,6 +101,6 @@ func applyEventSubscriptionsFromBaggageMember(m baggage.Member, e *Event) {
 			e.Subscriptions[i].Flags = make(map[string]string)
 		}
 
-		e.Subscriptions[i].Flags[split[4]] = m.Value()
+		e.Subscriptions[i].Flags[unescapeKey(strings.Join(split[4:], "."))] = m.Value()
 	}
 }
@@ -44,13 +44,13 @@ func TestInjectEventSubscriptionsToFlatMap(t *testing.T) {
 				"event.subscriptions[0].customer_id":   "subscription_0_customerid_test",
 				"event.subscriptions[0].plan_id":       "subscription_0_planid_test",
 				"event.subscriptions[0].usage":         "subscription_0_usage_test",
-				"event.subscriptions[0].increment_by":  "1.000000",
+				"event.subscriptions[0].increment_by":  "1",
 				"event.subscriptions[0].flags.version": "a",
 				"event.subscriptions[1].id":            "subscription_1_id_test",
 				"event.subscriptions[1].customer_id":   "subscription_1_customerid_test",
 				"event.subscriptions[1].plan_id":       "subscription_1_planid_test",
 				"event.subscriptions[1].usage":         "subscription_1_usage_test",
-				"event.subscriptions[1].increment_by":  "1.250000",
+				"event.subscriptions[1].increment_by":  "1.25",
 				"event.subscriptions[1].flags.version": "b",
 			},
 		},
@@ -138,7 +138,7 @@ func TestApplyEventSubscriptionsFromBaggageMember(t *testing.T) {
 		},
 		{
 			input: func() baggage.Member {
-				m, _ := baggage.NewMember("event.subscriptions.0.increment_by", "1.000000")
+				m, _ := baggage.NewMember("event.subscriptions.0.increment_by", "1")
 				return m
 			},
 			expected: &Event{
@@ -234,7 +234,7 @@ func TestApplyEventSubscriptionsFromBaggageMember(t *testing.T) {
 		},
 		{
 			input: func() baggage.Member {
-				m, _ := baggage.NewMember("event.subscriptions.1.increment_by", "1.250000")
+				m, _ := baggage.NewMember("event.subscriptions.1.increment_by", "1.25")
 				return m
 			},
 			expected: &Event{
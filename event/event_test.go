@@ -51,7 +51,7 @@ func TestInjectEventToFlatMap(t *testing.T) {
 				"event.subscriptions[0].customer_id":   "subscription_0_customerid_test",
 				"event.subscriptions[0].plan_id":       "subscription_0_planid_test",
 				"event.subscriptions[0].usage":         "subscription_0_usage_test",
-				"event.subscriptions[0].increment_by":  "1.000000",
+				"event.subscriptions[0].increment_by":  "1",
 				"event.subscriptions[0].flags.version": "a",
 			},
 		},
@@ -0,0 +1,150 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/event"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+/*
+Ensure *Enricher complies to the event.Enricher type.
+*/
+var _ event.Enricher = (*Enricher)(nil)
+
+/*
+Enricher is an event.Enricher looking up the IP address of events in a database
+in the MaxMind DB format.
+*/
+type Enricher struct {
+
+	// reader is the reader of the database opened.
+	reader *maxminddb.Reader
+
+	// language is the language of the names of cities, countries, and regions.
+	language string
+}
+
+/*
+WithEnricher allows to set optional values when opening a database with Open.
+*/
+type WithEnricher func(geo *Enricher)
+
+/*
+WithLanguage sets the language of the names of cities, countries, and regions,
+such as "fr" or "pt-BR". If a name is not available in this language, the English
+name is used. Defaults to "en".
+*/
+func WithLanguage(language string) WithEnricher {
+	return func(geo *Enricher) {
+		if language != "" {
+			geo.language = language
+		}
+	}
+}
+
+/*
+record is the subset of a City record read from the database.
+*/
+type record struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+		TimeZone  string  `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+}
+
+/*
+Open opens the database at the path passed. The database must be closed with Close
+once the Enricher is not used anymore.
+*/
+func Open(path string, opts ...WithEnricher) (*Enricher, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, errorstack.New(fmt.Sprintf("Failed to open GeoIP database at %q", path)).WithChildren(err)
+	}
+
+	geo := &Enricher{
+		reader:   reader,
+		language: "en",
+	}
+
+	for _, opt := range opts {
+		opt(geo)
+	}
+
+	return geo, nil
+}
+
+/*
+Enrich populates the Location of the Event passed from its IP address, if the
+Location is empty. The timezone of the Event is also populated from the location
+found, if empty. Events without IP address or with an IP address not found in the
+database are left untouched.
+*/
+func (geo *Enricher) Enrich(ctx context.Context, e *event.Event) error {
+	if e.IP == nil || (e.Location != (event.Location{}) && e.Timezone != "") {
+		return nil
+	}
+
+	var r record
+	if err := geo.reader.Lookup(e.IP, &r); err != nil {
+		return errorstack.New(fmt.Sprintf("Failed to look up IP address %q", e.IP.String())).WithChildren(err)
+	}
+
+	if e.Location == (event.Location{}) {
+		e.Location = event.Location{
+			City:      geo.name(r.City.Names, ""),
+			Country:   geo.name(r.Country.Names, r.Country.ISOCode),
+			Latitude:  r.Location.Latitude,
+			Longitude: r.Location.Longitude,
+		}
+
+		if len(r.Subdivisions) > 0 {
+			e.Location.Region = geo.name(r.Subdivisions[0].Names, r.Subdivisions[0].ISOCode)
+		}
+	}
+
+	if e.Timezone == "" {
+		e.Timezone = r.Location.TimeZone
+	}
+
+	return nil
+}
+
+/*
+name returns the name in the language of the Enricher, or in English if not
+available. The fallback is returned if no name is available.
+*/
+func (geo *Enricher) name(names map[string]string, fallback string) string {
+	if name, ok := names[geo.language]; ok && name != "" {
+		return name
+	}
+
+	if name, ok := names["en"]; ok && name != "" {
+		return name
+	}
+
+	return fallback
+}
+
+/*
+Close closes the database. The Enricher must not be used anymore once closed.
+*/
+func (geo *Enricher) Close() error {
+	return geo.reader.Close()
+}
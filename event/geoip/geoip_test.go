@@ -0,0 +1,178 @@
+package geoip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.nunchi.studio/helix/event"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+pair is a key/value pair of a map encoded in the MaxMind DB format.
+*/
+type pair struct {
+	key   string
+	value any
+}
+
+/*
+encode appends the value passed to the buffer, using the data types of the MaxMind
+DB format.
+*/
+func encode(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case string:
+		buf.WriteByte(2<<5 | byte(len(v)))
+		buf.WriteString(v)
+	case float64:
+		buf.WriteByte(3<<5 | 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case uint16:
+		buf.WriteByte(5<<5 | 2)
+		binary.Write(buf, binary.BigEndian, v)
+	case uint32:
+		buf.WriteByte(6<<5 | 4)
+		binary.Write(buf, binary.BigEndian, v)
+	case []pair:
+		buf.WriteByte(7<<5 | byte(len(v)))
+		for _, p := range v {
+			encode(buf, p.key)
+			encode(buf, p.value)
+		}
+	case []any:
+		buf.WriteByte(byte(len(v)))
+		buf.WriteByte(11 - 7)
+		for _, item := range v {
+			encode(buf, item)
+		}
+	}
+}
+
+/*
+writeDatabase writes an IPv4 database in the MaxMind DB format where 81.0.0.0/8
+is located in Paris, and returns its path.
+*/
+func writeDatabase(t *testing.T) string {
+	var buf bytes.Buffer
+
+	// The search tree has one node per bit of the prefix, with 24 bits records.
+	// The left record is followed for 0, the right one for 1. A record equal to
+	// the number of nodes means no data, a greater one points to the data section.
+	const prefix, nodes = 81, 8
+	for i := 0; i < nodes; i++ {
+		next := uint32(i + 1)
+		if i == nodes-1 {
+			next = nodes + 16
+		}
+
+		records := [2]uint32{nodes, nodes}
+		records[(prefix>>(7-i))&1] = next
+		for _, r := range records {
+			buf.Write([]byte{byte(r >> 16), byte(r >> 8), byte(r)})
+		}
+	}
+
+	buf.Write(make([]byte, 16))
+	encode(&buf, []pair{
+		{"city", []pair{{"names", []pair{{"en", "Paris"}}}}},
+		{"country", []pair{{"iso_code", "FR"}, {"names", []pair{{"en", "France"}, {"fr", "France"}}}}},
+		{"subdivisions", []any{[]pair{{"iso_code", "IDF"}, {"names", []pair{{"en", "Île-de-France"}}}}}},
+		{"location", []pair{{"latitude", 48.8566}, {"longitude", 2.3522}, {"time_zone", "Europe/Paris"}}},
+	})
+
+	buf.WriteString("\xAB\xCD\xEFMaxMind.com")
+	encode(&buf, []pair{
+		{"binary_format_major_version", uint16(2)},
+		{"binary_format_minor_version", uint16(0)},
+		{"database_type", "City"},
+		{"ip_version", uint16(4)},
+		{"node_count", uint32(nodes)},
+		{"record_size", uint16(24)},
+	})
+
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	return path
+}
+
+func TestEnricher_Enrich(t *testing.T) {
+	geo, err := Open(writeDatabase(t))
+	require.NoError(t, err)
+	defer geo.Close()
+
+	testcases := []struct {
+		input    event.Event
+		expected event.Event
+	}{
+		{
+			input:    event.Event{},
+			expected: event.Event{},
+		},
+		{
+			input: event.Event{
+				IP: net.ParseIP("10.0.0.1"),
+			},
+			expected: event.Event{
+				IP: net.ParseIP("10.0.0.1"),
+			},
+		},
+		{
+			input: event.Event{
+				IP: net.ParseIP("81.2.69.142"),
+			},
+			expected: event.Event{
+				IP:       net.ParseIP("81.2.69.142"),
+				Timezone: "Europe/Paris",
+				Location: event.Location{
+					City:      "Paris",
+					Country:   "France",
+					Region:    "Île-de-France",
+					Latitude:  48.8566,
+					Longitude: 2.3522,
+				},
+			},
+		},
+		{
+			input: event.Event{
+				IP:       net.ParseIP("81.2.69.142"),
+				Timezone: "Europe/London",
+				Location: event.Location{
+					City: "London",
+				},
+			},
+			expected: event.Event{
+				IP:       net.ParseIP("81.2.69.142"),
+				Timezone: "Europe/London",
+				Location: event.Location{
+					City: "London",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		actual := tc.input
+		err := geo.Enrich(context.Background(), &actual)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, actual)
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0o600))
+
+	_, err := Open(path)
+	assert.ErrorContains(t, err, "Failed to open GeoIP database")
+}
@@ -0,0 +1,17 @@
+/*
+Package geoip exposes an event.Enricher populating the Location and timezone of
+events from their IP address, using a local database in the MaxMind DB format,
+such as GeoLite2 City or GeoIP2 City. The database is not downloaded nor updated
+by this package: it must be provided by the service, for example by mounting it
+in the container.
+
+Example:
+
+	geo, err := geoip.Open("/usr/share/GeoIP/GeoLite2-City.mmdb")
+	if err != nil {
+	  return err
+	}
+
+	event.SetEnrichers(event.UserAgentEnricher{}, geo)
+*/
+package geoip
@@ -0,0 +1,321 @@
+package event
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+/*
+WithHTTPRequest allows to set optional values when building an Event from an
+HTTP request with FromHTTPRequest.
+*/
+type WithHTTPRequest func(opts *httpOptions)
+
+/*
+httpOptions holds the options applied when building an Event from an HTTP request.
+*/
+type httpOptions struct {
+
+	// proxies is the list of trusted proxies.
+	proxies []netip.Prefix
+}
+
+/*
+WithTrustedProxies sets the network ranges of the proxies trusted to forward the
+client's details. The "Forwarded", "X-Forwarded-For", "X-Real-IP",
+"X-Forwarded-Proto", and "X-Forwarded-Host" headers are only honoured when the
+request comes from one of these proxies, since they can be forged by any client
+otherwise.
+
+Example:
+
+	event.WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))
+*/
+func WithTrustedProxies(proxies ...netip.Prefix) WithHTTPRequest {
+	return func(opts *httpOptions) {
+		opts.proxies = append(opts.proxies, proxies...)
+	}
+}
+
+/*
+FromHTTPRequest builds an Event from the HTTP request passed. It sets the IP,
+user agent, and locale of the client, as well as the Page, Referrer, and Campaign
+(from "utm_*" query parameters) objects. The user agent is also parsed to set
+the App, Device, and OS objects. The name of the Event is not set, and shall be
+set by the caller.
+
+Example:
+
+	e := event.FromHTTPRequest(req)
+	e.Name = "subscribed"
+
+	ctx := event.ContextWithEvent(req.Context(), e)
+*/
+func FromHTTPRequest(req *http.Request, opts ...WithHTTPRequest) Event {
+	o := &httpOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	remote := remoteAddr(req)
+	trusted := o.trusts(remote)
+
+	e := Event{
+		IP:        clientIP(req, remote, trusted, o),
+		UserAgent: req.UserAgent(),
+		Locale:    acceptLanguage(req.Header.Get("Accept-Language")),
+		Page:      pageFromRequest(req, trusted),
+		Referrer:  referrerFromURL(req.Referer()),
+		Campaign:  campaignFromQuery(req.URL.Query()),
+	}
+
+	e.App, e.Device, e.OS = parseUserAgent(e.UserAgent)
+	return e
+}
+
+/*
+trusts informs if the address passed is one of the trusted proxies.
+*/
+func (o *httpOptions) trusts(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+
+	for _, prefix := range o.proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+remoteAddr returns the address of the peer that sent the request.
+*/
+func remoteAddr(req *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	addr, _ := netip.ParseAddr(host)
+	return addr.Unmap()
+}
+
+/*
+clientIP returns the IP of the client. When the request comes from a trusted
+proxy, forwarding headers are walked from the closest hop to the farthest one,
+and the first address that is not a trusted proxy is the client.
+*/
+func clientIP(req *http.Request, remote netip.Addr, trusted bool, o *httpOptions) net.IP {
+	if trusted {
+		var hops []string
+		for _, value := range req.Header.Values("Forwarded") {
+			hops = append(hops, forwardedFor(value)...)
+		}
+
+		if len(hops) == 0 {
+			for _, value := range req.Header.Values("X-Forwarded-For") {
+				hops = append(hops, strings.Split(value, ",")...)
+			}
+		}
+
+		if len(hops) == 0 {
+			hops = append(hops, req.Header.Get("X-Real-IP"))
+		}
+
+		var farthest netip.Addr
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := parseHop(hops[i])
+			if err != nil {
+				break
+			}
+
+			farthest = addr
+			if !o.trusts(addr) {
+				return net.IP(addr.AsSlice())
+			}
+		}
+
+		if farthest.IsValid() {
+			return net.IP(farthest.AsSlice())
+		}
+	}
+
+	if !remote.IsValid() {
+		return nil
+	}
+
+	return net.IP(remote.AsSlice())
+}
+
+/*
+forwardedFor returns the "for" parameters of the "Forwarded" header value passed,
+as defined in RFC 7239.
+*/
+func forwardedFor(value string) []string {
+	var hops []string
+	for _, element := range strings.Split(value, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				hops = append(hops, strings.Trim(val, `"`))
+			}
+		}
+	}
+
+	return hops
+}
+
+/*
+parseHop parses an address found in a forwarding header, which may include a
+port and brackets for IPv6.
+*/
+func parseHop(hop string) (netip.Addr, error) {
+	hop = strings.TrimSpace(hop)
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(hop, "[]"))
+	return addr.Unmap(), err
+}
+
+/*
+acceptLanguage returns the language with the highest quality in the value of an
+"Accept-Language" header. Returns an empty string if none is found.
+
+Example:
+
+	"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5"
+
+Will produce:
+
+	"fr-CH"
+*/
+func acceptLanguage(value string) string {
+	var locale string
+	var best float64
+	for _, part := range strings.Split(value, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if key, val, ok := strings.Cut(strings.TrimSpace(params), "="); ok && key == "q" {
+			q, _ = strconv.ParseFloat(val, 64)
+		}
+
+		if q > best {
+			locale, best = tag, q
+		}
+	}
+
+	return locale
+}
+
+/*
+pageFromRequest returns the Page requested. The scheme and host forwarded are
+only used if the request comes from a trusted proxy.
+*/
+func pageFromRequest(req *http.Request, trusted bool) Page {
+	scheme, host := "http", req.Host
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	if trusted {
+		if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		}
+
+		if forwarded := req.Header.Get("X-Forwarded-Host"); forwarded != "" {
+			host = forwarded
+		}
+	}
+
+	page := Page{
+		Path:     req.URL.Path,
+		Referrer: req.Referer(),
+	}
+
+	if req.URL.RawQuery != "" {
+		page.Search = "?" + req.URL.RawQuery
+	}
+
+	if host != "" {
+		u := url.URL{
+			Scheme:   scheme,
+			Host:     host,
+			Path:     req.URL.Path,
+			RawPath:  req.URL.RawPath,
+			RawQuery: req.URL.RawQuery,
+		}
+
+		page.URL = u.String()
+	}
+
+	return page
+}
+
+/*
+referrers maps the domains of well-known referrers to their type.
+*/
+var referrers = map[string]string{
+	"bing":       "search",
+	"duckduckgo": "search",
+	"google":     "search",
+	"yahoo":      "search",
+	"facebook":   "social",
+	"instagram":  "social",
+	"linkedin":   "social",
+	"reddit":     "social",
+	"t":          "social",
+	"twitter":    "social",
+	"x":          "social",
+	"youtube":    "social",
+}
+
+/*
+referrerFromURL returns the Referrer given the value of a "Referer" header. The
+type is set for well-known search engines and social networks.
+*/
+func referrerFromURL(value string) Referrer {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return Referrer{}
+	}
+
+	ref := Referrer{
+		Name: u.Hostname(),
+		URL:  value,
+	}
+
+	// Only look at the second-level domain, so "www.google.com" and "google.fr"
+	// are both matched.
+	labels := strings.Split(strings.TrimPrefix(ref.Name, "www."), ".")
+	if len(labels) >= 2 {
+		ref.Type = referrers[labels[len(labels)-2]]
+	}
+
+	return ref
+}
+
+/*
+campaignFromQuery returns the Campaign given the "utm_*" query parameters.
+*/
+func campaignFromQuery(query url.Values) Campaign {
+	return Campaign{
+		Name:    query.Get("utm_campaign"),
+		Source:  query.Get("utm_source"),
+		Medium:  query.Get("utm_medium"),
+		Term:    query.Get("utm_term"),
+		Content: query.Get("utm_content"),
+	}
+}
@@ -0,0 +1,98 @@
+package event
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromHTTPRequest(t *testing.T) {
+	proxies := WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))
+
+	testcases := []struct {
+		request  func() *http.Request
+		opts     []WithHTTPRequest
+		expected Event
+	}{
+		{
+			request: func() *http.Request {
+				req := httptest.NewRequest("GET", "http://example.com/pricing?utm_source=newsletter&utm_medium=email&utm_campaign=launch", nil)
+				req.RemoteAddr = "203.0.113.7:52100"
+				req.Header.Set("Accept-Language", "en;q=0.8, fr-CH, fr;q=0.9, *;q=0.5")
+				req.Header.Set("Referer", "https://www.google.com/search?q=helix")
+				req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+				return req
+			},
+			expected: Event{
+				IP:     net.ParseIP("203.0.113.7").To4(),
+				Locale: "fr-CH",
+				Page: Page{
+					Path:     "/pricing",
+					Referrer: "https://www.google.com/search?q=helix",
+					Search:   "?utm_source=newsletter&utm_medium=email&utm_campaign=launch",
+					URL:      "http://example.com/pricing?utm_source=newsletter&utm_medium=email&utm_campaign=launch",
+				},
+				Referrer: Referrer{
+					Type: "search",
+					Name: "www.google.com",
+					URL:  "https://www.google.com/search?q=helix",
+				},
+				Campaign: Campaign{
+					Name:   "launch",
+					Source: "newsletter",
+					Medium: "email",
+				},
+			},
+		},
+		{
+			request: func() *http.Request {
+				req := httptest.NewRequest("GET", "http://internal:8080/", nil)
+				req.RemoteAddr = "10.0.0.2:52100"
+				req.Header.Set("X-Forwarded-For", "1.2.3.4, 198.51.100.1, 10.0.0.1")
+				req.Header.Set("X-Forwarded-Proto", "https")
+				req.Header.Set("X-Forwarded-Host", "example.com")
+				req.Header.Set("User-Agent", "curl/8.4.0")
+
+				return req
+			},
+			opts: []WithHTTPRequest{proxies},
+			expected: Event{
+				IP:        net.ParseIP("198.51.100.1").To4(),
+				UserAgent: "curl/8.4.0",
+				App:       App{Name: "curl", Version: "8.4.0"},
+				Page: Page{
+					Path: "/",
+					URL:  "https://example.com/",
+				},
+			},
+		},
+		{
+			request: func() *http.Request {
+				req := httptest.NewRequest("GET", "http://example.com/", nil)
+				req.RemoteAddr = "10.0.0.2:52100"
+				req.Header.Set("Forwarded", `for="[2001:db8::1]:4711";proto=https, for=10.0.0.1`)
+
+				return req
+			},
+			opts: []WithHTTPRequest{proxies},
+			expected: Event{
+				IP: net.ParseIP("2001:db8::1"),
+				Page: Page{
+					Path: "/",
+					URL:  "http://example.com/",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		actual := FromHTTPRequest(tc.request(), tc.opts...)
+
+		assert.Equal(t, tc.expected, actual)
+	}
+}
@@ -50,7 +50,12 @@ func EventFromJSON(input json.RawMessage) (Event, bool) {
 
 /*
 ToFlatMap returns a flatten map for a given Event. Keys are prefixed with "event.",
-and struct level are separated by a ".". All values are stringified.
+and struct level are separated by a ".". All values are stringified, and floats
+use their shortest representation so they are parsed back to the exact same value.
+Keys of Meta, Params, and flags have their characters other than letters, digits,
+"_", and "-" percent-encoded, so "utm.source" becomes "utm%2Esource". Converting
+the flat map back to an Event is lossless, except for empty maps and slices that
+are restored as nil.
 
 This is primarily designed for the telemetry packages, allowing to pass contextual
 information about an event using Go's context or HTTP headers, but can be useful
@@ -6,6 +6,7 @@ an Event through a context.Context in order to trace them from end-to-end.
 helix.go core and integrations rely on Go contexts to manage logs and traces
 across services.
 
-This package must not import any other package of this ecosystem.
+This package must not import any other package of this ecosystem, except errorstack
+which doesn't import any.
 */
 package event
@@ -0,0 +1,236 @@
+package event
+
+import (
+	"math"
+	"math/rand"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+/*
+randomEvent is an Event generated randomly by testing/quick. Empty maps and
+slices are generated as nil, since they are equivalent once propagated.
+*/
+type randomEvent struct {
+	Event
+}
+
+/*
+Generate implements quick.Generator. Sizes are kept small so the Baggage built
+from the Event stays within the limits of the W3C Baggage specification.
+*/
+func (randomEvent) Generate(r *rand.Rand, _ int) reflect.Value {
+	e := Event{
+		ID:          randomString(r),
+		Name:        randomString(r),
+		Meta:        randomMap(r),
+		IsAnonymous: r.Intn(2) == 0,
+		UserID:      randomString(r),
+		GroupID:     randomString(r),
+		TenantID:    randomString(r),
+		UserAgent:   randomString(r),
+		Locale:      randomString(r),
+		Timezone:    randomString(r),
+		App:         App{Name: randomString(r), Version: randomString(r), BuildID: randomString(r)},
+		Campaign:    Campaign{Name: randomString(r), Source: randomString(r), Medium: randomString(r), Term: randomString(r), Content: randomString(r)},
+		Cloud:       Cloud{Provider: randomString(r), Service: randomString(r), Region: randomString(r), ProjectID: randomString(r), AccountID: randomString(r)},
+		Device:      Device{ID: randomString(r), Manufacturer: randomString(r), Model: randomString(r), Name: randomString(r), Type: randomString(r), Version: randomString(r), AdvertisingID: randomString(r)},
+		Library:     Library{Name: randomString(r), Version: randomString(r)},
+		Location:    Location{City: randomString(r), Country: randomString(r), Region: randomString(r), Latitude: randomFloat(r), Longitude: randomFloat(r), Speed: randomFloat(r)},
+		Network:     Network{Bluetooth: r.Intn(2) == 0, Cellular: r.Intn(2) == 0, WIFI: r.Intn(2) == 0, Carrier: randomString(r)},
+		OS:          OS{Name: randomString(r), Arch: randomString(r), Version: randomString(r)},
+		Page:        Page{Path: randomString(r), Referrer: randomString(r), Search: randomString(r), Title: randomString(r), URL: randomString(r)},
+		Referrer:    Referrer{Type: randomString(r), Name: randomString(r), URL: randomString(r), Link: randomString(r)},
+		Screen:      Screen{Density: r.Int63n(5) - 2, Width: r.Int63(), Height: -r.Int63()},
+	}
+
+	if n := r.Intn(3); n > 0 {
+		e.Params = make(url.Values)
+		for i := 0; i < n; i++ {
+			values := make([]string, 1+r.Intn(3))
+			for j := range values {
+				values[j] = randomString(r)
+			}
+
+			e.Params[randomString(r)] = values
+		}
+	}
+
+	switch r.Intn(3) {
+	case 1:
+		e.IP = net.IPv4(byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
+	case 2:
+		e.IP = make(net.IP, net.IPv6len)
+		r.Read(e.IP)
+		e.IP[0] = 0x20
+	}
+
+	if r.Intn(2) == 0 {
+		e.Timestamp = time.Unix(r.Int63n(1<<35), r.Int63n(int64(time.Second))).UTC()
+	}
+
+	for i := r.Intn(3); i > 0; i-- {
+		e.Subscriptions = append(e.Subscriptions, Subscription{
+			ID:          randomString(r),
+			TenantID:    randomString(r),
+			CustomerID:  randomString(r),
+			PlanID:      randomString(r),
+			Usage:       randomString(r),
+			IncrementBy: randomFloat(r),
+			Flags:       randomMap(r),
+		})
+	}
+
+	return reflect.ValueOf(randomEvent{e})
+}
+
+/*
+alphabet contains the characters used to generate strings, including the ones
+used as separators in the flat map and values that used to be dropped.
+*/
+var alphabet = []string{"a", "Z", "0", "1", ".", "[", "]", "%", "=", ",", ";", " ", "é", "🚀", "false", "0.000000", "<nil>"}
+
+/*
+randomString returns a short random string, which may be empty.
+*/
+func randomString(r *rand.Rand) string {
+	var b strings.Builder
+	for i := r.Intn(4); i > 0; i-- {
+		b.WriteString(alphabet[r.Intn(len(alphabet))])
+	}
+
+	return b.String()
+}
+
+/*
+randomMap returns a small random map of strings, or nil.
+*/
+func randomMap(r *rand.Rand) map[string]string {
+	n := r.Intn(3)
+	if n == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		m[randomString(r)] = randomString(r)
+	}
+
+	return m
+}
+
+/*
+randomFloat returns a random float, including zero and values that can not be
+represented with a fixed number of decimals.
+*/
+func randomFloat(r *rand.Rand) float64 {
+	switch r.Intn(4) {
+	case 0:
+		return 0
+	case 1:
+		return math.SmallestNonzeroFloat64
+	case 2:
+		return -r.NormFloat64() * 1e-9
+	}
+
+	return r.NormFloat64() * 1e6
+}
+
+/*
+baggageFromFlatMap builds a Baggage from a flat map the same way the tracer does,
+and encodes and parses it to go through the same steps as across services.
+*/
+func baggageFromFlatMap(t *testing.T, flatten map[string]string) baggage.Baggage {
+	var members []baggage.Member
+	for k, v := range flatten {
+		k = strings.ReplaceAll(k, "[", ".")
+		k = strings.ReplaceAll(k, "].", ".")
+		k = strings.ReplaceAll(k, "]", "")
+
+		m, err := baggage.NewMemberRaw(k, v)
+		require.NoError(t, err)
+		members = append(members, m)
+	}
+
+	b, err := baggage.New(members...)
+	require.NoError(t, err)
+
+	parsed, err := baggage.Parse(b.String())
+	require.NoError(t, err)
+
+	return parsed
+}
+
+func TestFlatMapRoundTrip(t *testing.T) {
+	property := func(input randomEvent) bool {
+		actual := extractEventFromBaggage(baggageFromFlatMap(t, ToFlatMap(input.Event)))
+
+		return assert.Equal(t, input.Event, actual)
+	}
+
+	err := quick.Check(property, &quick.Config{
+		MaxCount: 500,
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestFlatMapRoundTrip_Zero(t *testing.T) {
+	testcases := []Event{
+		{
+			Name: "0",
+			Meta: map[string]string{
+				"enabled":    "false",
+				"count":      "0",
+				"empty":      "",
+				"utm.source": "newsletter",
+			},
+			Params: url.Values{
+				"filters.in": []string{"0", "", "false"},
+			},
+			Location: Location{
+				Latitude:  0.0000001,
+				Longitude: -0.000000042,
+			},
+			Subscriptions: []Subscription{
+				{
+					ID: "sub_2N6YZQXgQAv87zMmvlHxePCSsRs",
+					Flags: map[string]string{
+						"beta.access": "0",
+					},
+				},
+				{},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		actual := extractEventFromBaggage(baggageFromFlatMap(t, ToFlatMap(tc)))
+
+		assert.Equal(t, tc, actual)
+	}
+}
+
+func TestExtractEventFromBaggage_Malformed(t *testing.T) {
+	var members []baggage.Member
+	for _, key := range []string{"event.app", "event.subscriptions.99999999999", "event.subscriptions.-1.id", "event.params.a.b", "event.params.a"} {
+		m, _ := baggage.NewMemberRaw(key, "value")
+		members = append(members, m)
+	}
+
+	b, err := baggage.New(members...)
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		extractEventFromBaggage(b)
+	})
+}
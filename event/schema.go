@@ -0,0 +1,234 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Schema describes the expected shape of events with a given name. Services shall
+declare the events they emit with Register, so events with the same name always
+have the same shape across services.
+*/
+type Schema struct {
+
+	// Meta is the list of keys that must be set in the Meta of the event.
+	Meta []string
+
+	// Params is the list of keys that must be set in the Params of the event.
+	Params []string
+
+	// Objects is the list of objects that must be set and not be empty in the
+	// event, by their JSON key.
+	//
+	// Example:
+	//
+	//   []string{"page", "subscriptions"}
+	Objects []string
+
+	// Strict rejects keys in Meta and Params that are not declared in the schema.
+	Strict bool
+}
+
+/*
+objects maps the JSON key of each object of an Event to the function informing
+if it is empty.
+*/
+var objects = map[string]func(e Event) bool{
+	"app":           func(e Event) bool { return reflect.ValueOf(e.App).IsZero() },
+	"campaign":      func(e Event) bool { return reflect.ValueOf(e.Campaign).IsZero() },
+	"cloud":         func(e Event) bool { return reflect.ValueOf(e.Cloud).IsZero() },
+	"device":        func(e Event) bool { return reflect.ValueOf(e.Device).IsZero() },
+	"library":       func(e Event) bool { return reflect.ValueOf(e.Library).IsZero() },
+	"location":      func(e Event) bool { return reflect.ValueOf(e.Location).IsZero() },
+	"network":       func(e Event) bool { return reflect.ValueOf(e.Network).IsZero() },
+	"os":            func(e Event) bool { return reflect.ValueOf(e.OS).IsZero() },
+	"page":          func(e Event) bool { return reflect.ValueOf(e.Page).IsZero() },
+	"referrer":      func(e Event) bool { return reflect.ValueOf(e.Referrer).IsZero() },
+	"screen":        func(e Event) bool { return reflect.ValueOf(e.Screen).IsZero() },
+	"subscriptions": func(e Event) bool { return len(e.Subscriptions) == 0 },
+}
+
+/*
+schemas holds the schemas registered, by event name.
+*/
+var schemas = struct {
+	mutex  sync.RWMutex
+	byName map[string]Schema
+}{
+	byName: make(map[string]Schema),
+}
+
+/*
+Register registers the schema of the events with the name passed. It overrides
+the schema previously registered for the name, if any. It panics if an object of
+the schema is not an object of Event, since this is a programming error.
+
+Example:
+
+	event.Register("subscribed", event.Schema{
+	  Meta:    []string{"plan"},
+	  Objects: []string{"subscriptions"},
+	})
+*/
+func Register(name string, schema Schema) {
+	for _, object := range schema.Objects {
+		if _, ok := objects[object]; !ok {
+			panic(fmt.Sprintf("event: object %q of schema %q is not an object of Event", object, name))
+		}
+	}
+
+	schemas.mutex.Lock()
+	defer schemas.mutex.Unlock()
+
+	schemas.byName[name] = schema
+}
+
+/*
+Validate validates the event passed against the schema registered for its name.
+Events with a name that has no schema registered are always valid, except if they
+have no name. This doesn't return a standard error, so validations can easily be
+added to an existing errorstack.
+*/
+func Validate(e Event) []errorstack.Validation {
+	var validations []errorstack.Validation
+	if e.Name == "" {
+		validations = append(validations, errorstack.Validation{
+			Message: "Name must be set and not be empty",
+			Code:    "required",
+			Path:    []string{"event", "name"},
+		})
+
+		return validations
+	}
+
+	schemas.mutex.RLock()
+	schema, exists := schemas.byName[e.Name]
+	schemas.mutex.RUnlock()
+
+	if !exists {
+		return validations
+	}
+
+	for _, key := range schema.Meta {
+		if e.Meta[key] == "" {
+			validations = append(validations, errorstack.Validation{
+				Message: fmt.Sprintf("Meta %q must be set and not be empty for event %q", key, e.Name),
+				Code:    "required",
+				Path:    []string{"event", "meta", key},
+			})
+		}
+	}
+
+	for _, key := range schema.Params {
+		if len(e.Params[key]) == 0 {
+			validations = append(validations, errorstack.Validation{
+				Message: fmt.Sprintf("Params %q must be set and not be empty for event %q", key, e.Name),
+				Code:    "required",
+				Path:    []string{"event", "params", key},
+			})
+		}
+	}
+
+	for _, object := range schema.Objects {
+		if objects[object](e) {
+			validations = append(validations, errorstack.Validation{
+				Message: fmt.Sprintf("Object %q must be set and not be empty for event %q", object, e.Name),
+				Code:    "required",
+				Path:    []string{"event", object},
+			})
+		}
+	}
+
+	if schema.Strict {
+		for _, key := range sortedKeys(e.Meta) {
+			if !slices.Contains(schema.Meta, key) {
+				validations = append(validations, errorstack.Validation{
+					Message: fmt.Sprintf("Meta %q is not declared for event %q", key, e.Name),
+					Code:    "unknown",
+					Path:    []string{"event", "meta", key},
+				})
+			}
+		}
+
+		for _, key := range sortedKeys(e.Params) {
+			if !slices.Contains(schema.Params, key) {
+				validations = append(validations, errorstack.Validation{
+					Message: fmt.Sprintf("Params %q is not declared for event %q", key, e.Name),
+					Code:    "unknown",
+					Path:    []string{"event", "params", key},
+				})
+			}
+		}
+	}
+
+	return validations
+}
+
+/*
+sortedKeys returns the keys of the map passed, sorted so validations are always
+returned in the same order.
+*/
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+/*
+Enforcement is the policy applied to events not valid against their schema when
+they are added to a context or propagated across services.
+*/
+type Enforcement string
+
+/*
+Policies that can be applied to events not valid against their schema.
+*/
+const (
+
+	// EnforcementNone doesn't validate events automatically. Events can still be
+	// validated with Validate. This is the default policy.
+	EnforcementNone Enforcement = ""
+
+	// EnforcementDrop drops events not valid against their schema: they are not
+	// added to contexts with ContextWithEvent once named, not returned by
+	// EventFromContext when built from a Baggage, and not propagated by
+	// integrations.
+	EnforcementDrop Enforcement = "drop"
+)
+
+/*
+enforcement is the policy currently applied.
+*/
+var enforcement atomic.Value
+
+/*
+SetEnforcement sets the policy applied to events not valid against their schema.
+*/
+func SetEnforcement(policy Enforcement) {
+	enforcement.Store(policy)
+}
+
+/*
+Enforce informs if the event passed can be added to a context or propagated given
+the enforcement policy applied. This is primarily designed for integrations that
+propagate events across services.
+*/
+func Enforce(e Event) bool {
+	policy, _ := enforcement.Load().(Enforcement)
+	if policy != EnforcementDrop {
+		return true
+	}
+
+	return len(Validate(e)) == 0
+}
@@ -0,0 +1,155 @@
+package event
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestValidate(t *testing.T) {
+	Register("schema.subscribed", Schema{
+		Meta:    []string{"plan"},
+		Params:  []string{"filters"},
+		Objects: []string{"subscriptions", "page"},
+		Strict:  true,
+	})
+
+	testcases := []struct {
+		input    Event
+		expected []errorstack.Validation
+	}{
+		{
+			input: Event{
+				Name: "schema.subscribed",
+				Meta: map[string]string{
+					"plan": "pro",
+				},
+				Params: url.Values{
+					"filters": []string{"a"},
+				},
+				Page: Page{
+					Path: "/pricing",
+				},
+				Subscriptions: []Subscription{
+					{
+						ID: "sub_2N6YZQXgQAv87zMmvlHxePCSsRs",
+					},
+				},
+			},
+			expected: nil,
+		},
+		{
+			input: Event{
+				Name: "schema.subscribed",
+				Meta: map[string]string{
+					"source": "email",
+				},
+				Params: url.Values{
+					"filters": []string{"a"},
+				},
+			},
+			expected: []errorstack.Validation{
+				{
+					Message: `Meta "plan" must be set and not be empty for event "schema.subscribed"`,
+					Code:    "required",
+					Path:    []string{"event", "meta", "plan"},
+				},
+				{
+					Message: `Object "subscriptions" must be set and not be empty for event "schema.subscribed"`,
+					Code:    "required",
+					Path:    []string{"event", "subscriptions"},
+				},
+				{
+					Message: `Object "page" must be set and not be empty for event "schema.subscribed"`,
+					Code:    "required",
+					Path:    []string{"event", "page"},
+				},
+				{
+					Message: `Meta "source" is not declared for event "schema.subscribed"`,
+					Code:    "unknown",
+					Path:    []string{"event", "meta", "source"},
+				},
+			},
+		},
+		{
+			input: Event{
+				Name: "schema.unregistered",
+				Meta: map[string]string{
+					"source": "email",
+				},
+			},
+			expected: nil,
+		},
+		{
+			input: Event{},
+			expected: []errorstack.Validation{
+				{
+					Message: "Name must be set and not be empty",
+					Code:    "required",
+					Path:    []string{"event", "name"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		actual := Validate(tc.input)
+
+		assert.Equal(t, tc.expected, actual)
+	}
+
+	assert.Panics(t, func() {
+		Register("schema.invalid", Schema{
+			Objects: []string{"unknown"},
+		})
+	})
+}
+
+func TestEnforce(t *testing.T) {
+	Register("schema.enforced", Schema{
+		Meta: []string{"plan"},
+	})
+
+	valid := Event{
+		Name: "schema.enforced",
+		Meta: map[string]string{
+			"plan": "pro",
+		},
+	}
+
+	invalid := Event{
+		Name: "schema.enforced",
+	}
+
+	assert.True(t, Enforce(invalid))
+
+	SetEnforcement(EnforcementDrop)
+	defer SetEnforcement(EnforcementNone)
+
+	assert.True(t, Enforce(valid))
+	assert.False(t, Enforce(invalid))
+
+	ctx := ContextWithEvent(context.Background(), invalid)
+	_, found := EventFromContext(ctx)
+	assert.False(t, found)
+
+	// An Event with no name is still being built, so it is not enforced yet.
+	ctx = ContextWithEvent(context.Background(), Event{UserID: "usr_1"})
+	_, found = EventFromContext(ctx)
+	assert.True(t, found)
+
+	ctx = ContextWithEvent(context.Background(), valid)
+	actual, found := EventFromContext(ctx)
+	assert.True(t, found)
+	assert.Equal(t, valid, actual)
+
+	member, _ := baggage.NewMember("event.name", "schema.enforced")
+	b, _ := baggage.New(member)
+	_, found = EventFromContext(baggage.ContextWithBaggage(context.Background(), b))
+	assert.False(t, found)
+}
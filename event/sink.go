@@ -0,0 +1,430 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Sink is the interface a backend must implement to receive the events tracked with
+Track, such as an analytics or a data warehouse. Events are buffered and sent in
+batches, so Send receives one or more events at once. Send is retried when it
+returns an error, so it should be idempotent by relying on the ID of events.
+*/
+type Sink interface {
+	Send(ctx context.Context, events []Event) error
+}
+
+/*
+WithSink allows to set optional values when adding a Sink with AddSink.
+*/
+type WithSink func(opts *sinkOptions)
+
+/*
+sinkOptions holds the options applied to a Sink.
+*/
+type sinkOptions struct {
+
+	// batchSize is the maximum number of events sent at once.
+	batchSize int
+
+	// bufferSize is the maximum number of events buffered before Track blocks.
+	bufferSize int
+
+	// interval is the maximum duration an event stays buffered.
+	interval time.Duration
+
+	// retries is the maximum number of retries when a batch can not be sent.
+	retries int
+
+	// backoff is the duration to wait before the first retry. It is doubled for
+	// each subsequent retry.
+	backoff time.Duration
+
+	// timeout is the maximum duration of each attempt to send a batch.
+	timeout time.Duration
+}
+
+/*
+WithBatchSize sets the maximum number of events sent at once to the Sink. Defaults
+to 100.
+*/
+func WithBatchSize(size int) WithSink {
+	return func(opts *sinkOptions) {
+		if size > 0 {
+			opts.batchSize = size
+		}
+	}
+}
+
+/*
+WithBufferSize sets the maximum number of events buffered for the Sink. When the
+buffer is full, Track blocks until there is room or its context is done. Defaults
+to 10000.
+*/
+func WithBufferSize(size int) WithSink {
+	return func(opts *sinkOptions) {
+		if size > 0 {
+			opts.bufferSize = size
+		}
+	}
+}
+
+/*
+WithFlushInterval sets the maximum duration an event stays buffered before being
+sent to the Sink, even if the batch is not full. Defaults to 5 seconds.
+*/
+func WithFlushInterval(interval time.Duration) WithSink {
+	return func(opts *sinkOptions) {
+		if interval > 0 {
+			opts.interval = interval
+		}
+	}
+}
+
+/*
+WithRetries sets the maximum number of retries when a batch can not be sent to
+the Sink, and the duration to wait before the first retry. The duration is doubled
+for each subsequent retry. Defaults to 3 retries, starting after 100 milliseconds.
+*/
+func WithRetries(retries int, backoff time.Duration) WithSink {
+	return func(opts *sinkOptions) {
+		if retries >= 0 {
+			opts.retries = retries
+		}
+
+		if backoff > 0 {
+			opts.backoff = backoff
+		}
+	}
+}
+
+/*
+WithSendTimeout sets the maximum duration of each attempt to send a batch to the
+Sink. Once reached, the context passed to Send is done and the attempt is considered
+failed. Defaults to 10 seconds.
+*/
+func WithSendTimeout(timeout time.Duration) WithSink {
+	return func(opts *sinkOptions) {
+		if timeout > 0 {
+			opts.timeout = timeout
+		}
+	}
+}
+
+/*
+tracker buffers the events tracked for a Sink, and sends them in batches from its
+own goroutine.
+*/
+type tracker struct {
+
+	// name is the name the Sink has been added with.
+	name string
+
+	// sink is the Sink events are sent to.
+	sink Sink
+
+	// opts holds the options applied to the Sink.
+	opts sinkOptions
+
+	// queue buffers the events tracked.
+	queue chan Event
+
+	// flush receives requests to send the events buffered. The context is used to
+	// send the events, and the error is sent back once done.
+	flush chan flushRequest
+
+	// stop is closed to stop the goroutine once flushed.
+	stop chan struct{}
+
+	// stopped is closed once the goroutine has stopped.
+	stopped chan struct{}
+}
+
+/*
+flushRequest is a request to send the events buffered by a tracker.
+*/
+type flushRequest struct {
+	ctx   context.Context
+	reply chan error
+}
+
+/*
+sinks holds the trackers of the sinks added, by name.
+*/
+var sinks = struct {
+	mutex  sync.RWMutex
+	byName map[string]*tracker
+}{
+	byName: make(map[string]*tracker),
+}
+
+/*
+AddSink adds a Sink receiving the events tracked with Track, with the name passed.
+Returns an error if a Sink has already been added with the same name.
+
+Example:
+
+	err := event.AddSink("segment", &event.SegmentSink{
+	  WriteKey: os.Getenv("SEGMENT_WRITE_KEY"),
+	}, event.WithBatchSize(50))
+*/
+func AddSink(name string, sink Sink, opts ...WithSink) error {
+	o := sinkOptions{
+		batchSize:  100,
+		bufferSize: 10000,
+		interval:   5 * time.Second,
+		retries:    3,
+		backoff:    100 * time.Millisecond,
+		timeout:    10 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sinks.mutex.Lock()
+	defer sinks.mutex.Unlock()
+
+	if _, exists := sinks.byName[name]; exists {
+		return errorstack.New(fmt.Sprintf("Sink %q has already been added", name), errorstack.WithCode(errorstack.CodeAlreadyExists))
+	}
+
+	t := &tracker{
+		name:    name,
+		sink:    sink,
+		opts:    o,
+		queue:   make(chan Event, o.bufferSize),
+		flush:   make(chan flushRequest),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	sinks.byName[name] = t
+	go t.run()
+
+	return nil
+}
+
+/*
+RemoveSink sends the events buffered for the Sink added with the name passed, and
+removes it. Returns an error if the events could not be sent, or if the Sink is
+still sending events once the context is done.
+*/
+func RemoveSink(ctx context.Context, name string) error {
+	sinks.mutex.Lock()
+	t, exists := sinks.byName[name]
+	delete(sinks.byName, name)
+	sinks.mutex.Unlock()
+
+	if !exists {
+		return nil
+	}
+
+	err := t.requestFlush(ctx)
+	close(t.stop)
+
+	select {
+	case <-t.stopped:
+	case <-ctx.Done():
+		if err == nil {
+			err = errorstack.New(fmt.Sprintf("Failed to remove sink %q", name)).WithChildren(ctx.Err())
+		}
+	}
+
+	return err
+}
+
+/*
+trackers returns the trackers of the sinks added, sorted by name.
+*/
+func trackers() []*tracker {
+	sinks.mutex.RLock()
+	defer sinks.mutex.RUnlock()
+
+	list := make([]*tracker, 0, len(sinks.byName))
+	for _, t := range sinks.byName {
+		list = append(list, t)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+
+	return list
+}
+
+/*
+run buffers the events tracked and sends them in batches, either when a batch is
+full, when the flush interval is reached, or when a flush is requested.
+*/
+func (t *tracker) run() {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(t.opts.interval)
+	defer ticker.Stop()
+
+	var batch []Event
+	for {
+		select {
+		case e := <-t.queue:
+			batch = append(batch, e)
+			if len(batch) >= t.opts.batchSize {
+				t.report(t.send(context.Background(), batch))
+				batch = nil
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				t.report(t.send(context.Background(), batch))
+				batch = nil
+			}
+
+		case req := <-t.flush:
+			batch = append(batch, t.drain()...)
+
+			// Do not keep events that could not be sent, so a failing Sink can not
+			// grow the memory indefinitely.
+			var errs []error
+			for len(batch) > 0 {
+				n := min(len(batch), t.opts.batchSize)
+				if err := t.send(req.ctx, batch[:n]); err != nil {
+					errs = append(errs, err)
+				}
+
+				batch = batch[n:]
+			}
+
+			batch = nil
+			if len(errs) > 0 {
+				req.reply <- errorstack.New(fmt.Sprintf("Failed to flush sink %q", t.name)).WithChildren(errs...)
+				continue
+			}
+
+			req.reply <- nil
+
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+/*
+drain returns the events currently buffered in the queue, without waiting for new
+ones.
+*/
+func (t *tracker) drain() []Event {
+	var events []Event
+	for {
+		select {
+		case e := <-t.queue:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+/*
+requestFlush requests the goroutine to send the events buffered, and waits until
+they are sent or the context is done.
+*/
+func (t *tracker) requestFlush(ctx context.Context) error {
+	req := flushRequest{
+		ctx:   ctx,
+		reply: make(chan error, 1),
+	}
+
+	select {
+	case t.flush <- req:
+	case <-ctx.Done():
+		return errorstack.New(fmt.Sprintf("Failed to flush sink %q", t.name)).WithChildren(ctx.Err())
+	}
+
+	select {
+	case err := <-req.reply:
+		return err
+	case <-ctx.Done():
+		return errorstack.New(fmt.Sprintf("Failed to flush sink %q", t.name)).WithChildren(ctx.Err())
+	}
+}
+
+/*
+send sends the batch passed to the Sink, and retries with an exponential backoff
+if it fails. Returns an error once all retries have failed or if the context is
+done.
+*/
+func (t *tracker) send(ctx context.Context, batch []Event) error {
+	backoff := t.opts.backoff
+	for attempt := 0; ; attempt++ {
+		err := t.sendOnce(ctx, batch)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= t.opts.retries {
+			return t.failed(len(batch), err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return t.failed(len(batch), err)
+		}
+
+		backoff *= 2
+	}
+}
+
+/*
+sendOnce sends the batch passed to the Sink a single time, within the send timeout.
+A panic is converted into an error, so the goroutine of the tracker keeps running
+and the panic is retried and reported just like any other error.
+*/
+func (t *tracker) sendOnce(ctx context.Context, batch []Event) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, t.opts.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			stack := errorstack.New(fmt.Sprintf("Sink %q panicked", t.name))
+			stack.WithValidations(errorstack.Validation{
+				Message: fmt.Sprintf("%v", r),
+			})
+
+			// Keep the error the Sink panicked with, if any, so it can still be
+			// matched with errors.Is and errors.As.
+			if cause, ok := r.(error); ok {
+				stack.Cause = cause
+			}
+
+			err = stack
+		}
+	}()
+
+	return t.sink.Send(ctx, batch)
+}
+
+/*
+failed returns an error for events that could not be sent to the Sink.
+*/
+func (t *tracker) failed(events int, err error) error {
+	return errorstack.New(fmt.Sprintf("Failed to send %d events to sink %q", events, t.name)).WithChildren(err)
+}
+
+/*
+report passes the error to the handler set with SetErrorHandler, if any. Errors
+are reported this way when events are sent in the background.
+*/
+func (t *tracker) report(err error) {
+	if err != nil {
+		handleError(err)
+	}
+}
@@ -0,0 +1,87 @@
+package event
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+Ensure *MemorySink complies to the Sink type.
+*/
+var _ Sink = (*MemorySink)(nil)
+
+/*
+MemorySink is a Sink keeping the events sent in memory. It is designed to be used
+as a stand-in for real sinks in tests and local environments. The zero value is
+ready to use.
+
+Example:
+
+	sink := &event.MemorySink{}
+	event.AddSink("memory", sink)
+
+	event.Track(ctx, e)
+	event.Flush(ctx)
+
+	events := sink.Events()
+*/
+type MemorySink struct {
+
+	// mutex allows to lock/unlock access to the events.
+	mutex sync.Mutex
+
+	// events holds the events sent, in the order they have been sent.
+	events []Event
+
+	// err is returned by Send when set, allowing to simulate a failing Sink.
+	err error
+}
+
+/*
+Send keeps the events passed in memory, or returns the error set with SetError.
+*/
+func (s *MemorySink) Send(ctx context.Context, events []Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	s.events = append(s.events, events...)
+	return nil
+}
+
+/*
+Events returns a copy of the events sent to the Sink.
+*/
+func (s *MemorySink) Events() []Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	events := make([]Event, len(s.events))
+	copy(events, s.events)
+
+	return events
+}
+
+/*
+SetError sets the error returned by Send, allowing to simulate a failing Sink.
+Pass nil to make Send succeed again.
+*/
+func (s *MemorySink) SetError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.err = err
+}
+
+/*
+Reset removes the events sent to the Sink.
+*/
+func (s *MemorySink) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.events = nil
+}
@@ -0,0 +1,221 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Ensure *SegmentSink complies to the Sink type.
+*/
+var _ Sink = (*SegmentSink)(nil)
+
+/*
+SegmentSink is a Sink sending events as "track" calls to the batch endpoint of
+Segment, or of any Segment-compatible API such as RudderStack or Jitsu.
+
+The Meta of an Event are sent as properties, alongside its Params, Subscriptions,
+and tenant ID. Other objects are sent in the context.
+
+Example:
+
+	err := event.AddSink("segment", &event.SegmentSink{
+	  WriteKey: os.Getenv("SEGMENT_WRITE_KEY"),
+	})
+*/
+type SegmentSink struct {
+
+	// Endpoint is the URL of the batch endpoint.
+	//
+	// Default:
+	//
+	//   "https://api.segment.io/v1/batch"
+	Endpoint string
+
+	// WriteKey is the write key of the source, used to authenticate requests.
+	WriteKey string
+
+	// Client is the HTTP client used to send requests. Defaults to a client with
+	// a timeout of 10 seconds.
+	Client *http.Client
+}
+
+/*
+segmentBatch is the body of a request to the batch endpoint.
+*/
+type segmentBatch struct {
+	Batch  []segmentMessage `json:"batch"`
+	SentAt time.Time        `json:"sentAt"`
+}
+
+/*
+segmentMessage is a "track" call of the Segment specification.
+*/
+type segmentMessage struct {
+	Type        string         `json:"type"`
+	Event       string         `json:"event"`
+	MessageID   string         `json:"messageId"`
+	UserID      string         `json:"userId,omitempty"`
+	AnonymousID string         `json:"anonymousId,omitempty"`
+	Timestamp   time.Time      `json:"timestamp"`
+	Properties  map[string]any `json:"properties,omitempty"`
+	Context     segmentContext `json:"context"`
+}
+
+/*
+segmentContext is the context of a call of the Segment specification.
+*/
+type segmentContext struct {
+	IP        net.IP    `json:"ip,omitempty"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	Locale    string    `json:"locale,omitempty"`
+	Timezone  string    `json:"timezone,omitempty"`
+	GroupID   string    `json:"groupId,omitempty"`
+	App       *App      `json:"app,omitempty"`
+	Campaign  *Campaign `json:"campaign,omitempty"`
+	Device    *Device   `json:"device,omitempty"`
+	Library   *Library  `json:"library,omitempty"`
+	Location  *Location `json:"location,omitempty"`
+	Network   *Network  `json:"network,omitempty"`
+	OS        *OS       `json:"os,omitempty"`
+	Page      *Page     `json:"page,omitempty"`
+	Referrer  *Referrer `json:"referrer,omitempty"`
+	Screen    *Screen   `json:"screen,omitempty"`
+}
+
+/*
+Send sends the events passed in a single request to the batch endpoint. Returns
+an error if the request failed or if the status code is not 2xx.
+*/
+func (s *SegmentSink) Send(ctx context.Context, events []Event) error {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://api.segment.io/v1/batch"
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+
+	body := segmentBatch{
+		Batch:  make([]segmentMessage, len(events)),
+		SentAt: time.Now().UTC(),
+	}
+
+	for i, e := range events {
+		body.Batch[i] = toSegmentMessage(e)
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(s.WriteKey, "")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return errorstack.New(fmt.Sprintf("Segment endpoint responded with status %d: %s", res.StatusCode, bytes.TrimSpace(msg)), errorstack.WithCode(errorstack.CodeFromHTTPStatus(res.StatusCode)))
+	}
+
+	return nil
+}
+
+/*
+toSegmentMessage returns the "track" call of the Event passed. Segment requires
+either a user ID or an anonymous ID, so the ID of the Event is used as anonymous
+ID if the Event has no user ID.
+*/
+func toSegmentMessage(e Event) segmentMessage {
+	msg := segmentMessage{
+		Type:      "track",
+		Event:     e.Name,
+		MessageID: e.ID,
+		Timestamp: e.Timestamp,
+		Context: segmentContext{
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			Locale:    e.Locale,
+			Timezone:  e.Timezone,
+			GroupID:   e.GroupID,
+			App:       nonZero(e.App),
+			Campaign:  nonZero(e.Campaign),
+			Device:    nonZero(e.Device),
+			Library:   nonZero(e.Library),
+			Location:  nonZero(e.Location),
+			Network:   nonZero(e.Network),
+			OS:        nonZero(e.OS),
+			Page:      nonZero(e.Page),
+			Referrer:  nonZero(e.Referrer),
+			Screen:    nonZero(e.Screen),
+		},
+	}
+
+	switch {
+	case e.UserID == "":
+		msg.AnonymousID = e.ID
+	case e.IsAnonymous:
+		msg.AnonymousID = e.UserID
+	default:
+		msg.UserID = e.UserID
+	}
+
+	properties := make(map[string]any)
+	for k, v := range e.Meta {
+		properties[k] = v
+	}
+
+	if len(e.Params) > 0 {
+		properties["params"] = e.Params
+	}
+
+	if len(e.Subscriptions) > 0 {
+		properties["subscriptions"] = e.Subscriptions
+	}
+
+	if e.TenantID != "" {
+		properties["tenant_id"] = e.TenantID
+	}
+
+	if len(properties) > 0 {
+		msg.Properties = properties
+	}
+
+	return msg
+}
+
+/*
+nonZero returns a pointer to the object passed, or nil if it is the zero value so
+it is omitted once encoded.
+*/
+func nonZero[T comparable](object T) *T {
+	var zero T
+	if object == zero {
+		return nil
+	}
+
+	return &object
+}
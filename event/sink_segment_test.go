@@ -0,0 +1,73 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentSink(t *testing.T) {
+	var received segmentBatch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, _, ok := r.BasicAuth()
+		if !ok || key != "write_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+
+	defer server.Close()
+
+	events := []Event{
+		{
+			ID:        "evt_1",
+			Name:      "subscribed",
+			UserID:    "user_1",
+			TenantID:  "tenant_1",
+			Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Meta: map[string]string{
+				"plan": "pro",
+			},
+			App: App{
+				Name: "helix",
+			},
+		},
+		{
+			ID:   "evt_2",
+			Name: "unsubscribed",
+		},
+	}
+
+	sink := &SegmentSink{
+		Endpoint: server.URL,
+		WriteKey: "write_key",
+	}
+
+	require.NoError(t, sink.Send(context.Background(), events))
+	require.Len(t, received.Batch, 2)
+
+	assert.Equal(t, "track", received.Batch[0].Type)
+	assert.Equal(t, "subscribed", received.Batch[0].Event)
+	assert.Equal(t, "evt_1", received.Batch[0].MessageID)
+	assert.Equal(t, "user_1", received.Batch[0].UserID)
+	assert.Equal(t, map[string]any{"plan": "pro", "tenant_id": "tenant_1"}, received.Batch[0].Properties)
+	assert.Equal(t, &App{Name: "helix"}, received.Batch[0].Context.App)
+	assert.Nil(t, received.Batch[0].Context.Device)
+
+	assert.Empty(t, received.Batch[1].UserID)
+	assert.Equal(t, "evt_2", received.Batch[1].AnonymousID)
+
+	sink.WriteKey = "invalid"
+	err := sink.Send(context.Background(), events)
+	assert.Equal(t, errorstack.CodeUnauthenticated, errorstack.CodeOf(err))
+}
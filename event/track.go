@@ -0,0 +1,115 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+errorHandler is the function called when events sent in the background could not
+be sent to a Sink, or could not be enriched.
+*/
+var errorHandler atomic.Value
+
+/*
+SetErrorHandler sets the function called when events sent in the background could
+not be sent to a Sink, once all retries have failed. Errors encountered when
+flushing are returned by Flush instead. Errors returned by enrichers applied with
+Enrich are also passed to this function. The service package sets a handler logging
+these errors.
+*/
+func SetErrorHandler(handler func(err error)) {
+	errorHandler.Store(handler)
+}
+
+/*
+handleError passes the error to the handler set with SetErrorHandler, if any.
+*/
+func handleError(err error) {
+	handler, _ := errorHandler.Load().(func(err error))
+	if handler != nil {
+		handler(err)
+	}
+}
+
+/*
+Track buffers the Event passed so it is sent to every Sink added with AddSink.
+The ID and timestamp of the Event are set if empty, so sinks can deduplicate events
+when retrying. Track only blocks when the buffer of a Sink is full, until there
+is room or the context is done. Returns an error if the Event has no name, if it
+is dropped by the enforcement policy, or if it could not be buffered.
+
+Example:
+
+	err := event.Track(ctx, event.Event{
+	  Name:   "subscribed",
+	  UserID: "user_2N6YZQLcYy2SPtmHiII69yHp0WE",
+	})
+*/
+func Track(ctx context.Context, e Event) error {
+	if e.Name == "" || !Enforce(e) {
+		return errorstack.New("Failed to track event", errorstack.WithCode(errorstack.CodeInvalidArgument)).WithValidations(Validate(e)...)
+	}
+
+	if e.ID == "" {
+		e.ID = newID()
+	}
+
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+
+	stack := errorstack.New("Failed to track event")
+	for _, t := range trackers() {
+		select {
+		case t.queue <- e:
+		case <-ctx.Done():
+			stack.WithChildren(errorstack.New(fmt.Sprintf("Failed to buffer event for sink %q", t.name)).WithChildren(ctx.Err()))
+		}
+	}
+
+	if stack.HasChildren() {
+		return stack
+	}
+
+	return nil
+}
+
+/*
+Flush sends the events buffered to every Sink, and waits until they are sent or
+the context is done. It is automatically called when closing the default service,
+before closing its integrations and once each layer of integrations is closed, so
+events are not lost when the service stops. An integration tracking events should
+therefore depend on the integrations its sinks rely on, so it is closed before
+them.
+*/
+func Flush(ctx context.Context) error {
+	stack := errorstack.New("Failed to flush events")
+	for _, t := range trackers() {
+		if err := t.requestFlush(ctx); err != nil {
+			stack.WithChildren(err)
+		}
+	}
+
+	if stack.HasChildren() {
+		return stack
+	}
+
+	return nil
+}
+
+/*
+newID returns a random identifier for an Event.
+*/
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,228 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+flakySink is a Sink failing a given number of times before succeeding.
+*/
+type flakySink struct {
+	MemorySink
+	failures atomic.Int32
+	attempts atomic.Int32
+}
+
+func (s *flakySink) Send(ctx context.Context, events []Event) error {
+	s.attempts.Add(1)
+	if s.failures.Add(-1) >= 0 {
+		return errors.New("unavailable")
+	}
+
+	return s.MemorySink.Send(ctx, events)
+}
+
+/*
+blockingSink is a Sink blocking until it is released.
+*/
+type blockingSink struct {
+	release chan struct{}
+}
+
+func (s *blockingSink) Send(ctx context.Context, events []Event) error {
+	<-s.release
+	return nil
+}
+
+/*
+panickingSink is a Sink panicking a single time before succeeding.
+*/
+type panickingSink struct {
+	MemorySink
+	panicked atomic.Bool
+}
+
+func (s *panickingSink) Send(ctx context.Context, events []Event) error {
+	if !s.panicked.Swap(true) {
+		panic("unavailable")
+	}
+
+	return s.MemorySink.Send(ctx, events)
+}
+
+/*
+hangingSink is a Sink hanging until its context is done.
+*/
+type hangingSink struct{}
+
+func (s hangingSink) Send(ctx context.Context, events []Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestTrack(t *testing.T) {
+	ctx := context.Background()
+
+	sink := &MemorySink{}
+	require.NoError(t, AddSink("memory", sink, WithBatchSize(2), WithFlushInterval(time.Hour)))
+	defer RemoveSink(ctx, "memory")
+
+	err := AddSink("memory", sink)
+	assert.Equal(t, errorstack.CodeAlreadyExists, errorstack.CodeOf(err))
+
+	// Events are sent once a batch is full.
+	require.NoError(t, Track(ctx, Event{Name: "subscribed"}))
+	require.NoError(t, Track(ctx, Event{Name: "unsubscribed"}))
+	assert.Eventually(t, func() bool {
+		return len(sink.Events()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// Remaining events are sent when flushing.
+	require.NoError(t, Track(ctx, Event{ID: "evt_1", Name: "renewed"}))
+	require.NoError(t, Flush(ctx))
+
+	events := sink.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "subscribed", events[0].Name)
+	assert.NotEmpty(t, events[0].ID)
+	assert.False(t, events[0].Timestamp.IsZero())
+	assert.Equal(t, "evt_1", events[2].ID)
+
+	// Events not valid are rejected.
+	err = Track(ctx, Event{})
+	assert.Equal(t, errorstack.CodeInvalidArgument, errorstack.CodeOf(err))
+	assert.Len(t, sink.Events(), 3)
+}
+
+func TestTrack_Retries(t *testing.T) {
+	ctx := context.Background()
+
+	sink := &flakySink{}
+	sink.failures.Store(2)
+	require.NoError(t, AddSink("flaky", sink, WithRetries(2, time.Millisecond)))
+
+	require.NoError(t, Track(ctx, Event{Name: "subscribed"}))
+	require.NoError(t, Flush(ctx))
+	assert.Equal(t, int32(3), sink.attempts.Load())
+	assert.Len(t, sink.Events(), 1)
+
+	// Once all retries have failed, the error is returned when flushing.
+	sink.failures.Store(3)
+	require.NoError(t, Track(ctx, Event{Name: "subscribed"}))
+	err := RemoveSink(ctx, "flaky")
+	assert.ErrorContains(t, err, `Failed to flush sink "flaky"`)
+	assert.Len(t, sink.Events(), 1)
+}
+
+func TestTrack_ErrorHandler(t *testing.T) {
+	ctx := context.Background()
+
+	var mutex sync.Mutex
+	var reported []error
+	SetErrorHandler(func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		reported = append(reported, err)
+	})
+
+	defer SetErrorHandler(nil)
+
+	sink := &MemorySink{}
+	sink.SetError(errors.New("unavailable"))
+	require.NoError(t, AddSink("failing", sink, WithFlushInterval(10*time.Millisecond), WithRetries(0, time.Millisecond)))
+	defer RemoveSink(ctx, "failing")
+
+	require.NoError(t, Track(ctx, Event{Name: "subscribed"}))
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(reported) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.ErrorContains(t, reported[0], `Failed to send 1 events to sink "failing"`)
+}
+
+func TestTrack_BufferFull(t *testing.T) {
+	sink := &blockingSink{
+		release: make(chan struct{}),
+	}
+
+	require.NoError(t, AddSink("full", sink, WithBufferSize(1), WithBatchSize(1)))
+	defer RemoveSink(context.Background(), "full")
+	defer close(sink.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The first event blocks the Sink, the second fills the buffer.
+	for i := 0; i < 3; i++ {
+		if err := Track(ctx, Event{Name: "subscribed"}); err != nil {
+			assert.ErrorContains(t, err, `Failed to buffer event for sink "full"`)
+			return
+		}
+	}
+
+	t.Fatal("expected Track to fail once the buffer is full")
+}
+
+func TestTrack_Panic(t *testing.T) {
+	ctx := context.Background()
+
+	sink := &panickingSink{}
+	require.NoError(t, AddSink("panicking", sink, WithRetries(1, time.Millisecond)))
+	defer RemoveSink(ctx, "panicking")
+
+	// A panic is retried just like an error.
+	require.NoError(t, Track(ctx, Event{Name: "subscribed"}))
+	require.NoError(t, Flush(ctx))
+	assert.Len(t, sink.Events(), 1)
+
+	// Once all retries have failed, the panic is returned as an error.
+	require.NoError(t, RemoveSink(ctx, "panicking"))
+	require.NoError(t, AddSink("panicking-once", &panickingSink{}, WithRetries(0, time.Millisecond)))
+	require.NoError(t, Track(ctx, Event{Name: "subscribed"}))
+
+	err := RemoveSink(ctx, "panicking-once")
+	assert.ErrorContains(t, err, `Sink "panicking-once" panicked`)
+}
+
+func TestTrack_SendTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	require.NoError(t, AddSink("hanging", hangingSink{}, WithSendTimeout(10*time.Millisecond), WithRetries(0, time.Millisecond)))
+	defer RemoveSink(ctx, "hanging")
+
+	require.NoError(t, Track(ctx, Event{Name: "subscribed"}))
+	err := Flush(ctx)
+	assert.ErrorContains(t, err, `Failed to send 1 events to sink "hanging"`)
+}
+
+func TestRemoveSink_Timeout(t *testing.T) {
+	sink := &blockingSink{
+		release: make(chan struct{}),
+	}
+
+	defer close(sink.release)
+	require.NoError(t, AddSink("blocking", sink))
+	require.NoError(t, Track(context.Background(), Event{Name: "subscribed"}))
+
+	// Removing the Sink doesn't block once the context is done, even if the Sink
+	// doesn't respect it.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := RemoveSink(ctx, "blocking")
+	assert.ErrorContains(t, err, `Failed to flush sink "blocking"`)
+}
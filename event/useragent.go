@@ -0,0 +1,168 @@
+package event
+
+import (
+	"regexp"
+	"strings"
+)
+
+/*
+browsers is the ordered list of browsers detected in a user agent. Order matters
+since most browsers also advertise the products they are based on, such as Edge
+advertising both "Chrome" and "Safari".
+*/
+var browsers = []struct {
+	name    string
+	product string
+}{
+	{name: "Edge", product: "Edg"},
+	{name: "Edge", product: "EdgA"},
+	{name: "Edge", product: "EdgiOS"},
+	{name: "Opera", product: "OPR"},
+	{name: "Samsung Internet", product: "SamsungBrowser"},
+	{name: "Firefox", product: "Firefox"},
+	{name: "Firefox", product: "FxiOS"},
+	{name: "Chrome", product: "CriOS"},
+	{name: "Chrome", product: "Chrome"},
+	{name: "Safari", product: "Version"},
+}
+
+/*
+Regular expressions used to parse a user agent.
+*/
+var (
+	reProduct = regexp.MustCompile(`([A-Za-z][A-Za-z0-9._-]*)/([0-9][0-9A-Za-z._-]*)`)
+	reWindows = regexp.MustCompile(`Windows NT ([0-9.]+)`)
+	reIOS     = regexp.MustCompile(`(?:iPhone|CPU) OS ([0-9_]+)`)
+	reMacOS   = regexp.MustCompile(`Mac OS X ([0-9_.]+)`)
+	reAndroid = regexp.MustCompile(`Android ([0-9.]+)`)
+	reModel   = regexp.MustCompile(`Android [0-9.]+; (?:[a-z]{2}[-_][a-zA-Z]{2}; )?([^;)]+?)(?: Build/[^;)]*)?[;)]`)
+	reBot     = regexp.MustCompile(`(?i)bot|crawler|spider|slurp`)
+)
+
+/*
+windowsVersions maps the versions of Windows NT to their commercial name.
+*/
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+/*
+parseUserAgent parses the user agent passed to return the App, Device, and OS
+objects of an Event. The App is the browser used, or the application itself if
+the user agent is not one of a browser. Only well-known platforms are detected,
+so objects are left empty when they can not be determined.
+
+Example:
+
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+
+Will produce:
+
+	App{Name: "Safari", Version: "17.0"}
+	Device{Manufacturer: "Apple", Model: "iPhone", Type: "mobile"}
+	OS{Name: "iOS", Version: "17.0"}
+*/
+func parseUserAgent(ua string) (App, Device, OS) {
+	var app App
+	var device Device
+	var os OS
+	if ua == "" {
+		return app, device, os
+	}
+
+	products := make(map[string]string)
+	matches := reProduct.FindAllStringSubmatch(ua, -1)
+	for _, match := range matches {
+		products[match[1]] = match[2]
+	}
+
+	for _, browser := range browsers {
+		if version, ok := products[browser.product]; ok {
+			app = App{
+				Name:    browser.name,
+				Version: version,
+			}
+
+			break
+		}
+	}
+
+	// When no browser is detected, the first product is the application itself,
+	// such as "MyApp/1.2.3 (iPhone; iOS 17.0)" or "curl/8.4.0".
+	if app.Name == "" && len(matches) > 0 && matches[0][1] != "Mozilla" {
+		app = App{
+			Name:    matches[0][1],
+			Version: matches[0][2],
+		}
+	}
+
+	switch {
+	case strings.Contains(ua, "iPhone"):
+		device = Device{Manufacturer: "Apple", Model: "iPhone", Type: "mobile"}
+		os = OS{Name: "iOS", Version: submatch(reIOS, ua)}
+
+	case strings.Contains(ua, "iPad"):
+		device = Device{Manufacturer: "Apple", Model: "iPad", Type: "tablet"}
+		os = OS{Name: "iOS", Version: submatch(reIOS, ua)}
+
+	case strings.Contains(ua, "Android"):
+		device = Device{Model: submatch(reModel, ua), Type: "tablet"}
+		if strings.Contains(ua, "Mobile") {
+			device.Type = "mobile"
+		}
+
+		os = OS{Name: "Android", Version: submatch(reAndroid, ua)}
+
+	case strings.Contains(ua, "Windows"):
+		device = Device{Type: "desktop"}
+		os = OS{Name: "Windows", Version: submatch(reWindows, ua)}
+		if name, ok := windowsVersions[os.Version]; ok {
+			os.Version = name
+		}
+
+	case strings.Contains(ua, "Macintosh"):
+		device = Device{Manufacturer: "Apple", Model: "Mac", Type: "desktop"}
+		os = OS{Name: "macOS", Version: submatch(reMacOS, ua)}
+
+	case strings.Contains(ua, "CrOS"):
+		device = Device{Type: "desktop"}
+		os = OS{Name: "ChromeOS"}
+
+	case strings.Contains(ua, "Linux"):
+		device = Device{Type: "desktop"}
+		os = OS{Name: "Linux"}
+	}
+
+	switch {
+	case strings.Contains(ua, "x86_64"), strings.Contains(ua, "x64"), strings.Contains(ua, "amd64"):
+		os.Arch = "amd64"
+	case strings.Contains(ua, "arm64"), strings.Contains(ua, "aarch64"):
+		os.Arch = "arm64"
+	case strings.Contains(ua, "i686"), strings.Contains(ua, "i386"):
+		os.Arch = "386"
+	}
+
+	if reBot.MatchString(ua) {
+		device.Type = "bot"
+	}
+
+	return app, device, os
+}
+
+/*
+submatch returns the first submatch of the regular expression in the string
+passed, with underscores replaced by dots so versions are consistent.
+*/
+func submatch(re *regexp.Regexp, s string) string {
+	match := re.FindStringSubmatch(s)
+	if len(match) < 2 {
+		return ""
+	}
+
+	return strings.ReplaceAll(strings.TrimSpace(match[1]), "_", ".")
+}
@@ -0,0 +1,72 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	testcases := []struct {
+		input  string
+		app    App
+		device Device
+		os     OS
+	}{
+		{
+			input: "",
+		},
+		{
+			input:  "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+			app:    App{Name: "Safari", Version: "17.0"},
+			device: Device{Manufacturer: "Apple", Model: "iPhone", Type: "mobile"},
+			os:     OS{Name: "iOS", Version: "17.0"},
+		},
+		{
+			input:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			app:    App{Name: "Edge", Version: "120.0.2210.91"},
+			device: Device{Type: "desktop"},
+			os:     OS{Name: "Windows", Arch: "amd64", Version: "10"},
+		},
+		{
+			input:  "Mozilla/5.0 (Linux; Android 13; SM-S918B Build/TP1A.220624.014) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			app:    App{Name: "Chrome", Version: "120.0.0.0"},
+			device: Device{Model: "SM-S918B", Type: "mobile"},
+			os:     OS{Name: "Android", Version: "13"},
+		},
+		{
+			input:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7; rv:121.0) Gecko/20100101 Firefox/121.0",
+			app:    App{Name: "Firefox", Version: "121.0"},
+			device: Device{Manufacturer: "Apple", Model: "Mac", Type: "desktop"},
+			os:     OS{Name: "macOS", Version: "10.15.7"},
+		},
+		{
+			input:  "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			app:    App{Name: "Chrome", Version: "120.0.0.0"},
+			device: Device{Type: "desktop"},
+			os:     OS{Name: "Linux", Arch: "amd64"},
+		},
+		{
+			input:  "MyApp/1.2.3 (iPad; iOS 17.0)",
+			app:    App{Name: "MyApp", Version: "1.2.3"},
+			device: Device{Manufacturer: "Apple", Model: "iPad", Type: "tablet"},
+			os:     OS{Name: "iOS"},
+		},
+		{
+			input:  "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			device: Device{Type: "bot"},
+		},
+		{
+			input: "curl/8.4.0",
+			app:   App{Name: "curl", Version: "8.4.0"},
+		},
+	}
+
+	for _, tc := range testcases {
+		app, device, os := parseUserAgent(tc.input)
+
+		assert.Equal(t, tc.app, app, tc.input)
+		assert.Equal(t, tc.device, device, tc.input)
+		assert.Equal(t, tc.os, os, tc.input)
+	}
+}
@@ -43,7 +43,7 @@ Config is not valid or if the initialization failed.
 func Connect(cfg Config) (Bucket, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, err
 	}
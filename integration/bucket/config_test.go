@@ -86,7 +86,7 @@ func TestConfig_Sanitize(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		err := tc.before.sanitize()
+		err := tc.before.Sanitize()
 
 		assert.Equal(t, tc.before, tc.after)
 		assert.Equal(t, tc.err, err)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
 	"go.nunchi.studio/helix/service"
 	"go.nunchi.studio/helix/telemetry/trace"
 
@@ -44,7 +45,7 @@ error if Config is not valid or if the connection failed.
 func Connect(cfg Config) (ClickHouse, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +56,16 @@ func Connect(cfg Config) (ClickHouse, error) {
 		config: &cfg,
 	}
 
+	// Resolve the secrets referenced in Config, if any. Stop here if error
+	// validations were encountered.
+	ctx := context.Background()
+	stack.WithValidations(integration.ResolveSecret(ctx, &cfg.User, "Config", "User")...)
+	stack.WithValidations(integration.ResolveSecret(ctx, &cfg.Password, "Config", "Password")...)
+	stack.WithValidations(cfg.TLS.ResolveSecrets(ctx)...)
+	if stack.HasValidations() {
+		return nil, stack
+	}
+
 	// Set the default ClickHouse options.
 	var opts = &clickhouse.Options{
 		Protocol: clickhouse.Native,
@@ -70,7 +81,7 @@ func Connect(cfg Config) (ClickHouse, error) {
 	if cfg.TLS.Enabled {
 		var validations []errorstack.Validation
 
-		opts.TLS, validations = cfg.TLS.ToStandardTLS()
+		opts.TLS, validations = cfg.TLS.ToStandardTLS(cfg.Addresses...)
 		if len(validations) > 0 {
 			stack.WithValidations(validations...)
 		}
@@ -22,23 +22,27 @@ type Config struct {
 	// Default:
 	//
 	//   "default"
-	Database string `json:"-"`
+	Database string `json:"-" config:"database"`
 
 	// User is the user to use to connect to the database.
-	User string `json:"-"`
+	User string `json:"-" config:"user"`
 
-	// Password is the user's password to connect to the database.
-	Password string `json:"-"`
+	// Password is the user's password to connect to the database. It can also
+	// reference a secret resolved when connecting, such as:
+	//
+	//   "vault://secret/data/db#password"
+	//   "file:///run/secrets/db"
+	Password string `json:"-" config:"password"`
 
 	// TLSConfig configures TLS to communicate with the ClickHouse server.
 	TLS integration.ConfigTLS `json:"tls"`
 }
 
 /*
-sanitize sets default values - when applicable - and validates the configuration.
+Sanitize sets default values - when applicable - and validates the configuration.
 Returns an error if configuration is not valid.
 */
-func (cfg *Config) sanitize() error {
+func (cfg *Config) Sanitize() error {
 	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
 
 	if len(cfg.Addresses) == 0 {
@@ -0,0 +1,128 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/event"
+)
+
+/*
+Ensure *eventSink complies to the event.Sink type.
+*/
+var _ event.Sink = (*eventSink)(nil)
+
+/*
+eventSink is an event.Sink inserting events in a ClickHouse table with a batch.
+*/
+type eventSink struct {
+	conn  ClickHouse
+	table string
+}
+
+/*
+eventRow is the row inserted for an event. The complete event is also inserted
+as JSON, so no detail is lost.
+*/
+type eventRow struct {
+	ID          string    `ch:"id"`
+	Name        string    `ch:"name"`
+	UserID      string    `ch:"user_id"`
+	GroupID     string    `ch:"group_id"`
+	TenantID    string    `ch:"tenant_id"`
+	IsAnonymous bool      `ch:"is_anonymous"`
+	Timestamp   time.Time `ch:"timestamp"`
+	Event       string    `ch:"event"`
+}
+
+/*
+NewEventSink returns an event.Sink inserting the events tracked in the table
+passed, using a batch for each group of events. The table can be prefixed by its
+database, such as "analytics.events", and is quoted so it can not be used to alter
+the query. The table must have the following columns:
+
+	CREATE TABLE events (
+	  id           String,
+	  name         LowCardinality(String),
+	  user_id      String,
+	  group_id     String,
+	  tenant_id    String,
+	  is_anonymous Bool,
+	  timestamp    DateTime64(9, 'UTC'),
+	  event        String
+	)
+	ENGINE = ReplacingMergeTree
+	ORDER BY (name, timestamp, id);
+
+Since events are retried when a batch fails, a ReplacingMergeTree ordered by the
+ID of events allows to deduplicate them.
+
+Example:
+
+	err := event.AddSink("clickhouse", clickhouse.NewEventSink(conn, "events"))
+*/
+func NewEventSink(conn ClickHouse, table string) event.Sink {
+	return &eventSink{
+		conn:  conn,
+		table: table,
+	}
+}
+
+/*
+Send inserts the events passed in a single batch.
+*/
+func (s *eventSink) Send(ctx context.Context, events []event.Event) error {
+	b, err := s.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s", quoteIdentifier(s.table)))
+	if err != nil {
+		return errorstack.NewFromError(err, errorstack.WithIntegration(identifier))
+	}
+
+	for _, e := range events {
+		encoded, err := json.Marshal(e)
+		if err != nil {
+			b.Abort(ctx)
+			return errorstack.NewFromError(err, errorstack.WithIntegration(identifier))
+		}
+
+		err = b.Append(ctx, &eventRow{
+			ID:          e.ID,
+			Name:        e.Name,
+			UserID:      e.UserID,
+			GroupID:     e.GroupID,
+			TenantID:    e.TenantID,
+			IsAnonymous: e.IsAnonymous,
+			Timestamp:   e.Timestamp,
+			Event:       string(encoded),
+		})
+
+		if err != nil {
+			b.Abort(ctx)
+			return errorstack.NewFromError(err, errorstack.WithIntegration(identifier))
+		}
+	}
+
+	if err := b.Send(ctx); err != nil {
+		return errorstack.NewFromError(err, errorstack.WithIntegration(identifier))
+	}
+
+	return nil
+}
+
+/*
+quoteIdentifier returns the identifier passed quoted with backticks, such as a
+table optionally prefixed by its database. Backslashes and backticks are escaped.
+*/
+func quoteIdentifier(identifier string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+	parts := strings.Split(identifier, ".")
+	for i, part := range parts {
+		parts[i] = "`" + escaper.Replace(part) + "`"
+	}
+
+	return strings.Join(parts, ".")
+}
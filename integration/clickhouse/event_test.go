@@ -0,0 +1,164 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/event"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+fake is a minimal ClickHouse connection used for testing purposes, only supporting
+batches.
+*/
+type fake struct {
+	query string
+	batch *fakeBatch
+	err   error
+}
+
+func (f *fake) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fake) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	return nil
+}
+
+func (f *fake) PrepareBatch(ctx context.Context, query string) (Batch, error) {
+	f.query = query
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return f.batch, nil
+}
+
+func (f *fake) Exec(ctx context.Context, query string, args ...any) error {
+	return errors.New("not implemented")
+}
+
+func (f *fake) AsyncInsert(ctx context.Context, query string, wait bool) error {
+	return errors.New("not implemented")
+}
+
+/*
+fakeBatch is a minimal Batch used for testing purposes.
+*/
+type fakeBatch struct {
+	rows    []*eventRow
+	err     error
+	aborted bool
+	sent    bool
+}
+
+func (b *fakeBatch) Append(ctx context.Context, v any) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	b.rows = append(b.rows, v.(*eventRow))
+	return nil
+}
+
+func (b *fakeBatch) Abort(ctx context.Context) error { b.aborted = true; return nil }
+func (b *fakeBatch) Flush(ctx context.Context) error { return nil }
+func (b *fakeBatch) Send(ctx context.Context) error  { b.sent = true; return nil }
+func (b *fakeBatch) IsSent(ctx context.Context) bool { return b.sent }
+
+func TestEventSink_Send(t *testing.T) {
+	events := []event.Event{
+		{
+			ID:        "evt_1",
+			Name:      "subscribed",
+			UserID:    "usr_1",
+			Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:          "evt_2",
+			Name:        "renewed",
+			IsAnonymous: true,
+		},
+	}
+
+	testcases := []struct {
+		conn    *fake
+		table   string
+		query   string
+		rows    int
+		aborted bool
+		sent    bool
+		err     bool
+	}{
+		{
+			conn:  &fake{batch: &fakeBatch{}},
+			table: "events",
+			query: "INSERT INTO `events`",
+			rows:  2,
+			sent:  true,
+		},
+		{
+			conn:  &fake{batch: &fakeBatch{}},
+			table: "analytics.events",
+			query: "INSERT INTO `analytics`.`events`",
+			rows:  2,
+			sent:  true,
+		},
+		{
+			conn:  &fake{batch: &fakeBatch{}},
+			table: "events` SETTINGS async_insert=1 --",
+			query: "INSERT INTO `events\\` SETTINGS async_insert=1 --`",
+			rows:  2,
+			sent:  true,
+		},
+		{
+			conn:  &fake{err: errors.New("connection refused")},
+			table: "events",
+			query: "INSERT INTO `events`",
+			err:   true,
+		},
+		{
+			conn:    &fake{batch: &fakeBatch{err: errors.New("invalid row")}},
+			table:   "events",
+			query:   "INSERT INTO `events`",
+			aborted: true,
+			err:     true,
+		},
+	}
+
+	for _, tc := range testcases {
+		sink := NewEventSink(tc.conn, tc.table)
+		err := sink.Send(context.Background(), events)
+
+		assert.Equal(t, tc.query, tc.conn.query)
+		if tc.err {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+
+		if tc.conn.batch == nil {
+			continue
+		}
+
+		assert.Len(t, tc.conn.batch.rows, tc.rows)
+		assert.Equal(t, tc.aborted, tc.conn.batch.aborted)
+		assert.Equal(t, tc.sent, tc.conn.batch.sent)
+	}
+
+	conn := &fake{batch: &fakeBatch{}}
+	require.NoError(t, NewEventSink(conn, "events").Send(context.Background(), events))
+
+	row := conn.batch.rows[0]
+	assert.Equal(t, "evt_1", row.ID)
+	assert.Equal(t, "subscribed", row.Name)
+	assert.Equal(t, "usr_1", row.UserID)
+	assert.Contains(t, row.Event, `"evt_1"`)
+	assert.True(t, conn.batch.rows[1].IsAnonymous)
+}
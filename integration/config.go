@@ -1,9 +1,11 @@
 package integration
 
 import (
+	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"os"
+	"time"
 
 	"go.nunchi.studio/helix/errorstack"
 )
@@ -31,21 +33,95 @@ type ConfigTLS struct {
 
 	// CertFile is the relative or absolute path to the certificate file.
 	//
-	// Example:
+	// It can also reference a secret holding the PEM-encoded certificate. See
+	// ResolveSecrets for more details.
+	//
+	// Examples:
 	//
 	//   "./server.crt"
-	CertFile string `json:"-"`
+	//   "vault://pki/data/server#cert"
+	CertFile string `json:"-" config:"cert_file"`
 
 	// KeyFile is the relative or absolute path to the private key file.
 	//
-	// Example:
+	// It can also reference a secret holding the PEM-encoded private key. See
+	// ResolveSecrets for more details.
+	//
+	// Examples:
 	//
 	//   "./server.key"
-	KeyFile string `json:"-"`
+	//   "vault://pki/data/server#key"
+	KeyFile string `json:"-" config:"key_file"`
 
 	// RootCAFiles allows to provide the RootCAs pool from a list of filenames.
 	// This is not required by all integrations.
-	RootCAFiles []string `json:"-"`
+	RootCAFiles []string `json:"-" config:"root_ca_files"`
+
+	// ClientAuth is the policy for the authentication of clients with certificates,
+	// a.k.a. mutual TLS. This only applies to integrations acting as servers.
+	//
+	// Default:
+	//
+	//   integration.ClientAuthNone
+	ClientAuth ClientAuth `json:"client_auth,omitempty"`
+
+	// ClientCAFiles allows to provide the pool of CAs used to verify certificates
+	// of clients from a list of filenames. This is required when ClientAuth is
+	// ClientAuthVerifyIfGiven or ClientAuthVerify.
+	ClientCAFiles []string `json:"-" config:"client_ca_files"`
+
+	// MinVersion is the minimum TLS version accepted, either "1.2" or "1.3".
+	//
+	// Default:
+	//
+	//   "1.2"
+	MinVersion string `json:"min_version,omitempty"`
+
+	// CipherSuites is the list of cipher suites enabled for TLS 1.2, as named by
+	// Go's crypto/tls. Only secure cipher suites are supported. Cipher suites of
+	// TLS 1.3 are not configurable. Go's defaults are used if empty.
+	//
+	// Example:
+	//
+	//   []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}
+	CipherSuites []string `json:"cipher_suites,omitempty"`
+
+	// CurvePreferences is the list of elliptic curves enabled for key exchanges,
+	// in order of preference. Supported curves are "X25519", "P256", "P384", and
+	// "P521". Go's defaults are used if empty.
+	CurvePreferences []string `json:"curve_preferences,omitempty"`
+
+	// AllowedSANs pins the identities of peers. When set, the certificate of the
+	// peer must have at least one URI or DNS Subject Alternative Name in the list.
+	// A value ending with "*" matches any SAN starting with the same prefix, which
+	// is useful for SPIFFE IDs. This applies to servers for client certificates,
+	// and to clients for server certificates. Since only verified certificates
+	// can be pinned, servers must set ClientAuth to ClientAuthVerifyIfGiven or
+	// ClientAuthVerify, and clients must not set InsecureSkipVerify.
+	//
+	// Example:
+	//
+	//   []string{"spiffe://example.org/ns/payments/*"}
+	AllowedSANs []string `json:"allowed_sans,omitempty"`
+
+	// ReloadInterval is the minimum duration between two checks of CertFile,
+	// KeyFile, and RootCAFiles for changes. When a file has changed, the files are
+	// reloaded and validated before being used by the next TLS handshake, so
+	// rotated certificates don't require to restart the service. Files are never
+	// reloaded if negative.
+	//
+	// Default:
+	//
+	//   1 * time.Minute
+	ReloadInterval time.Duration `json:"reload_interval,omitempty"`
+
+	// certPEM is the PEM-encoded certificate, resolved from CertFile when it
+	// references a secret.
+	certPEM []byte
+
+	// keyPEM is the PEM-encoded private key, resolved from KeyFile when it
+	// references a secret.
+	keyPEM []byte
 }
 
 /*
@@ -62,77 +138,210 @@ func (cfg *ConfigTLS) Sanitize() []errorstack.Validation {
 		return validations
 	}
 
-	if cfg.CertFile == "" {
+	// CertFile and KeyFile are optional, such as when only verifying the server's
+	// certificate, but must be set together.
+	if cfg.CertFile == "" && cfg.KeyFile != "" {
 		validations = append(validations, errorstack.Validation{
-			Message: "CertFile must be set and not be empty",
+			Message: "CertFile must be set and not be empty when KeyFile is set",
 			Path:    []string{"Config", "TLS", "CertFile"},
 		})
 	}
 
-	if cfg.KeyFile == "" {
+	if cfg.KeyFile == "" && cfg.CertFile != "" {
 		validations = append(validations, errorstack.Validation{
-			Message: "KeyFile must be set and not be empty",
+			Message: "KeyFile must be set and not be empty when CertFile is set",
 			Path:    []string{"Config", "TLS", "KeyFile"},
 		})
 	}
 
+	validations = append(validations, cfg.sanitizePolicy()...)
 	return validations
 }
 
 /*
-ToStandardTLS tries to return a Go standard *tls.Config. Returns validation errors
+ResolveSecrets resolves CertFile and KeyFile when they reference a secret, such as:
+
+	"vault://pki/data/server#key"
+
+The secret resolved must be the PEM-encoded certificate or private key, and not a
+path to a file. Returns validation errors if a secret can not be resolved. This
+doesn't return a standard error since this function shall only be called by
+integrations. This allows to easily add error validations to an existing errorstack:
+
+	stack.WithValidations(cfg.TLS.ResolveSecrets(ctx)...)
+*/
+func (cfg *ConfigTLS) ResolveSecrets(ctx context.Context) []errorstack.Validation {
+	var validations []errorstack.Validation
+	if !cfg.Enabled {
+		return validations
+	}
+
+	cert := cfg.CertFile
+	validations = append(validations, ResolveSecret(ctx, &cert, "Config", "TLS", "CertFile")...)
+	if cert != cfg.CertFile {
+		cfg.certPEM = []byte(cert)
+	}
+
+	key := cfg.KeyFile
+	validations = append(validations, ResolveSecret(ctx, &key, "Config", "TLS", "KeyFile")...)
+	if key != cfg.KeyFile {
+		cfg.keyPEM = []byte(key)
+	}
+
+	return validations
+}
+
+/*
+keyPair returns the certificate and private key, either resolved from secrets or
+read from CertFile and KeyFile.
+*/
+func (cfg *ConfigTLS) keyPair() (tls.Certificate, error) {
+	if cfg.certPEM == nil && cfg.keyPEM == nil {
+		return tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	}
+
+	var err error
+	certPEM, keyPEM := cfg.certPEM, cfg.keyPEM
+	if certPEM == nil {
+		certPEM, err = os.ReadFile(cfg.CertFile)
+		if err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+
+	if keyPEM == nil {
+		keyPEM, err = os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+/*
+hasKeyPair informs if a certificate and private key are configured.
+*/
+func (cfg *ConfigTLS) hasKeyPair() bool {
+	return (cfg.CertFile != "" || cfg.certPEM != nil) && (cfg.KeyFile != "" || cfg.keyPEM != nil)
+}
+
+/*
+ToStandardTLS tries to return a Go standard *tls.Config for clients. The certificate
+presented to servers and the root CAs used to verify servers are reloaded when
+their files change. See ReloadInterval for more details. Returns validation errors
 if configuration is not valid. This doesn't return a standard error since this
 function shall only be called by integrations. This allows to easily add error
 validations to an existing errorstack.
+
+The addresses dialed by the integration should be passed, so servers dialed by
+IP address can be verified against their IP SANs when ServerName is empty. The
+address dialed is not known when verifying a server, so root CAs can only be
+reloaded if at most one IP address is passed. Otherwise, servers are verified by
+Go's crypto/tls against the address dialed, with the root CAs loaded initially.
 */
-func (cfg *ConfigTLS) ToStandardTLS() (*tls.Config, []errorstack.Validation) {
+func (cfg *ConfigTLS) ToStandardTLS(addresses ...string) (*tls.Config, []errorstack.Validation) {
 	var validations []errorstack.Validation
 	if !cfg.Enabled {
 		return nil, validations
 	}
 
-	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
-	if err != nil {
-		validations = append(validations, errorstack.Validation{
-			Message: err.Error(),
-		})
-
+	r, validations := newReloader(cfg)
+	if len(validations) > 0 {
 		return nil, validations
 	}
 
 	tlsConfig := &tls.Config{
-		ServerName:         cfg.ServerName,
-		InsecureSkipVerify: cfg.InsecureSkipVerify,
-		Certificates:       []tls.Certificate{cert},
+		ServerName:           cfg.ServerName,
+		InsecureSkipVerify:   cfg.InsecureSkipVerify,
+		GetClientCertificate: r.getClientCertificate,
+		RootCAs:              r.loaded.rootCAs,
 	}
 
-	if len(cfg.RootCAFiles) == 0 {
-		return tlsConfig, nil
+	cfg.applyPolicy(tlsConfig)
+
+	// Root CAs set in the standard configuration can not be reloaded, so servers
+	// are verified against the root CAs currently loaded instead. A server dialed
+	// by IP address can only be verified this way if it is the only IP address,
+	// since the address dialed is unknown when verifying the server.
+	ips := ipHosts(addresses)
+	reloadRootCAs := len(cfg.RootCAFiles) > 0 && r.interval >= 0 && !cfg.InsecureSkipVerify
+	if cfg.ServerName == "" && len(ips) > 1 {
+		reloadRootCAs = false
 	}
 
-	caCertPool := x509.NewCertPool()
-	for _, ca := range cfg.RootCAFiles {
-		caCert, err := os.ReadFile(ca)
-		if err != nil {
-			validations = append(validations, errorstack.Validation{
-				Message: err.Error(),
-			})
+	if reloadRootCAs {
+		tlsConfig.InsecureSkipVerify = true
+	}
 
-			continue
-		}
+	if reloadRootCAs || len(cfg.AllowedSANs) > 0 {
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			if reloadRootCAs {
+				if err := r.verifyServer(cs, ips); err != nil {
+					return err
+				}
+			}
 
-		ok := caCertPool.AppendCertsFromPEM(caCert)
-		if !ok {
-			validations = append(validations, errorstack.Validation{
-				Message: "Failed to append root certificate from pem",
-			})
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("tls: server did not provide a certificate")
+			}
+
+			return r.config.verifySAN(cs.PeerCertificates[0])
 		}
 	}
 
+	return tlsConfig, nil
+}
+
+/*
+ToStandardServerTLS tries to return a Go standard *tls.Config for servers. The
+certificate presented to clients and the CAs used to verify clients are reloaded
+when their files change. See ReloadInterval for more details. Returns validation
+errors if configuration is not valid. This doesn't return a standard error since
+this function shall only be called by integrations. This allows to easily add
+error validations to an existing errorstack.
+*/
+func (cfg *ConfigTLS) ToStandardServerTLS() (*tls.Config, []errorstack.Validation) {
+	var validations []errorstack.Validation
+	if !cfg.Enabled {
+		return nil, validations
+	}
+
+	r, validations := newReloader(cfg)
 	if len(validations) > 0 {
 		return nil, validations
 	}
 
-	tlsConfig.RootCAs = caCertPool
+	tlsConfig := &tls.Config{
+		GetCertificate: r.getCertificate,
+		ClientAuth:     clientAuthTypes[cfg.ClientAuth],
+		ClientCAs:      r.loaded.clientCAs,
+	}
+
+	cfg.applyPolicy(tlsConfig)
+
+	// Clients are only pinned if they present a certificate. Whether a certificate
+	// is required is up to the client authentication policy. Certificates that have
+	// not been verified are never trusted.
+	if len(cfg.AllowedSANs) > 0 {
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return nil
+			}
+
+			if len(cs.VerifiedChains) == 0 {
+				return errors.New("tls: client certificate has not been verified")
+			}
+
+			return r.config.verifySAN(cs.PeerCertificates[0])
+		}
+	}
+
+	// Client CAs set in the standard configuration can not be reloaded, so each
+	// connection uses the client CAs currently loaded instead.
+	if len(cfg.ClientCAFiles) > 0 && r.interval >= 0 {
+		tlsConfig.GetConfigForClient = r.getConfigForClient(tlsConfig.Clone())
+	}
+
 	return tlsConfig, nil
 }
@@ -28,14 +28,113 @@ func TestConfigTLS_Sanitize(t *testing.T) {
 			after: ConfigTLS{
 				Enabled: true,
 			},
+			validations: nil,
+		},
+		{
+			before: ConfigTLS{
+				Enabled:  true,
+				CertFile: "./client.crt",
+			},
+			after: ConfigTLS{
+				Enabled:  true,
+				CertFile: "./client.crt",
+			},
+			validations: []errorstack.Validation{
+				{
+					Message: "KeyFile must be set and not be empty when CertFile is set",
+					Path:    []string{"Config", "TLS", "KeyFile"},
+				},
+			},
+		},
+		{
+			before: ConfigTLS{
+				Enabled:          true,
+				ClientAuth:       ClientAuthVerify,
+				MinVersion:       "1.1",
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_RC4_128_SHA"},
+				CurvePreferences: []string{"X25519", "P224"},
+				AllowedSANs:      []string{"spiffe://example.org/*", "*"},
+			},
+			after: ConfigTLS{
+				Enabled:          true,
+				ClientAuth:       ClientAuthVerify,
+				MinVersion:       "1.1",
+				CipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_RC4_128_SHA"},
+				CurvePreferences: []string{"X25519", "P224"},
+				AllowedSANs:      []string{"spiffe://example.org/*", "*"},
+			},
 			validations: []errorstack.Validation{
 				{
-					Message: "CertFile must be set and not be empty",
-					Path:    []string{"Config", "TLS", "CertFile"},
+					Message: "ClientCAFiles must be set and not be empty for verifying client certificates",
+					Path:    []string{"Config", "TLS", "ClientCAFiles"},
 				},
 				{
-					Message: "KeyFile must be set and not be empty",
-					Path:    []string{"Config", "TLS", "KeyFile"},
+					Message: `MinVersion "1.1" is not supported`,
+					Path:    []string{"Config", "TLS", "MinVersion"},
+				},
+				{
+					Message: `Cipher suite "TLS_RSA_WITH_RC4_128_SHA" is not supported or not secure`,
+					Path:    []string{"Config", "TLS", "CipherSuites", "1"},
+				},
+				{
+					Message: `Curve "P224" is not supported`,
+					Path:    []string{"Config", "TLS", "CurvePreferences", "1"},
+				},
+				{
+					Message: "SAN must not be empty nor match any value",
+					Path:    []string{"Config", "TLS", "AllowedSANs", "1"},
+				},
+			},
+		},
+		{
+			before: ConfigTLS{
+				Enabled:    true,
+				ClientAuth: "always",
+			},
+			after: ConfigTLS{
+				Enabled:    true,
+				ClientAuth: "always",
+			},
+			validations: []errorstack.Validation{
+				{
+					Message: `ClientAuth "always" is not supported`,
+					Path:    []string{"Config", "TLS", "ClientAuth"},
+				},
+			},
+		},
+		{
+			before: ConfigTLS{
+				Enabled:     true,
+				ClientAuth:  ClientAuthRequire,
+				AllowedSANs: []string{"spiffe://example.org/*"},
+			},
+			after: ConfigTLS{
+				Enabled:     true,
+				ClientAuth:  ClientAuthRequire,
+				AllowedSANs: []string{"spiffe://example.org/*"},
+			},
+			validations: []errorstack.Validation{
+				{
+					Message: "AllowedSANs can only be set when client certificates are verified, with ClientAuth set to verify_if_given or verify",
+					Path:    []string{"Config", "TLS", "AllowedSANs"},
+				},
+			},
+		},
+		{
+			before: ConfigTLS{
+				Enabled:            true,
+				InsecureSkipVerify: true,
+				AllowedSANs:        []string{"spiffe://example.org/*"},
+			},
+			after: ConfigTLS{
+				Enabled:            true,
+				InsecureSkipVerify: true,
+				AllowedSANs:        []string{"spiffe://example.org/*"},
+			},
+			validations: []errorstack.Validation{
+				{
+					Message: "AllowedSANs can not be set when InsecureSkipVerify is true, since the server's certificate is not verified",
+					Path:    []string{"Config", "TLS", "AllowedSANs"},
 				},
 			},
 		},
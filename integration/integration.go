@@ -21,7 +21,8 @@ type Integration interface {
 	// function can be blocking, for starting a server or a worker for example.
 	// The service package executes each Start function of attached integrations
 	// in their own goroutine, and returns an error as soon as a goroutine returns
-	// a non-nil error.
+	// a non-nil error. A blocking Start function should come with the Readiness
+	// interface.
 	Start(ctx context.Context) error
 
 	// Close closes the connection with the integration, if applicable.
@@ -33,3 +34,35 @@ type Integration interface {
 	// on the underlying client.
 	Status(ctx context.Context) (int, error)
 }
+
+/*
+Dependent is an optional interface an Integration can implement to declare the
+integrations it depends on. The service package relies on it to start integrations
+in order — dependencies first — and to close them in the reverse order.
+*/
+type Dependent interface {
+
+	// DependsOn returns the string representations of the integrations this
+	// integration depends on. Every integration attached to the service with a
+	// matching name is considered a dependency.
+	//
+	// Example:
+	//
+	//   []string{"postgres", "nats"}
+	DependsOn() []string
+}
+
+/*
+Readiness is an optional interface an Integration can implement when its Start
+function is blocking, such as for starting a server or a worker. It allows the
+service package to know when the integration is actually ready. An integration
+not implementing Readiness is considered ready as soon as its Start function
+returns with no error.
+*/
+type Readiness interface {
+
+	// Ready returns a channel closed once the integration is ready, such as when
+	// a server accepts connections. The channel must never be closed if the
+	// integration fails to start.
+	Ready(ctx context.Context) <-chan struct{}
+}
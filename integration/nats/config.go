@@ -25,10 +25,10 @@ type Config struct {
 }
 
 /*
-sanitize sets default values - when applicable - and validates the configuration.
+Sanitize sets default values - when applicable - and validates the configuration.
 Returns an error if configuration is not valid.
 */
-func (cfg *Config) sanitize() error {
+func (cfg *Config) Sanitize() error {
 	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
 
 	if len(cfg.Addresses) == 0 {
@@ -0,0 +1,102 @@
+package nats
+
+import (
+	"errors"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+/*
+codes maps errors returned by the NATS client to their canonical code. Errors are
+matched in order using errors.Is.
+*/
+var codes = []struct {
+	target error
+	code   errorstack.Code
+}{
+	{jetstream.ErrKeyNotFound, errorstack.CodeNotFound},
+	{jetstream.ErrKeyDeleted, errorstack.CodeNotFound},
+	{jetstream.ErrNoKeysFound, errorstack.CodeNotFound},
+	{jetstream.ErrMsgNotFound, errorstack.CodeNotFound},
+	{jetstream.ErrStreamNotFound, errorstack.CodeNotFound},
+	{jetstream.ErrConsumerNotFound, errorstack.CodeNotFound},
+	{jetstream.ErrConsumerDoesNotExist, errorstack.CodeNotFound},
+	{jetstream.ErrBucketNotFound, errorstack.CodeNotFound},
+	{jetstream.ErrObjectNotFound, errorstack.CodeNotFound},
+	{jetstream.ErrKeyExists, errorstack.CodeConflict},
+	{jetstream.ErrMsgAlreadyAckd, errorstack.CodeConflict},
+	{jetstream.ErrStreamNameAlreadyInUse, errorstack.CodeAlreadyExists},
+	{jetstream.ErrConsumerExists, errorstack.CodeAlreadyExists},
+	{jetstream.ErrConsumerNameAlreadyInUse, errorstack.CodeAlreadyExists},
+	{jetstream.ErrBucketExists, errorstack.CodeAlreadyExists},
+	{jetstream.ErrObjectAlreadyExists, errorstack.CodeAlreadyExists},
+	{jetstream.ErrInvalidKey, errorstack.CodeInvalidArgument},
+	{jetstream.ErrInvalidBucketName, errorstack.CodeInvalidArgument},
+	{jetstream.ErrInvalidStreamName, errorstack.CodeInvalidArgument},
+	{jetstream.ErrInvalidConsumerName, errorstack.CodeInvalidArgument},
+	{jetstream.ErrInvalidSubject, errorstack.CodeInvalidArgument},
+	{nats.ErrBadSubject, errorstack.CodeInvalidArgument},
+	{jetstream.ErrMaxBytesExceeded, errorstack.CodeResourceExhausted},
+	{jetstream.ErrTooManyStalledMsgs, errorstack.CodeResourceExhausted},
+	{nats.ErrMaxPayload, errorstack.CodeResourceExhausted},
+	{nats.ErrSlowConsumer, errorstack.CodeResourceExhausted},
+	{nats.ErrTimeout, errorstack.CodeDeadlineExceeded},
+	{nats.ErrAuthorization, errorstack.CodeUnauthenticated},
+	{nats.ErrAuthExpired, errorstack.CodeUnauthenticated},
+	{nats.ErrAuthRevoked, errorstack.CodeUnauthenticated},
+	{jetstream.ErrJetStreamNotEnabled, errorstack.CodeUnavailable},
+	{jetstream.ErrJetStreamNotEnabledForAccount, errorstack.CodeUnavailable},
+	{jetstream.ErrNoStreamResponse, errorstack.CodeUnavailable},
+	{nats.ErrNoResponders, errorstack.CodeUnavailable},
+	{nats.ErrNoServers, errorstack.CodeUnavailable},
+	{nats.ErrConnectionClosed, errorstack.CodeUnavailable},
+	{nats.ErrConnectionDraining, errorstack.CodeUnavailable},
+	{nats.ErrConnectionReconnecting, errorstack.CodeUnavailable},
+	{nats.ErrDisconnected, errorstack.CodeUnavailable},
+	{nats.ErrStaleConnection, errorstack.CodeUnavailable},
+}
+
+/*
+Classify returns an error wrapping the error returned by the NATS client, with the
+canonical code matching it. The original error can still be matched with errors.Is
+and errors.As. Returns nil if the error passed is nil.
+
+Example:
+
+	entry, err := kv.Get(ctx, "key")
+	if err != nil {
+	  rest.WriteError(rw, req, nats.Classify(err))
+	  return
+	}
+*/
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return errorstack.NewFromError(err, errorstack.WithIntegration(identifier), errorstack.WithCode(classify(err)))
+}
+
+/*
+classify returns the canonical code of the error passed. JetStream API errors not
+known by the client are classified given their HTTP-like status code.
+*/
+func classify(err error) errorstack.Code {
+	for _, c := range codes {
+		if errors.Is(err, c.target) {
+			return c.code
+		}
+	}
+
+	var apiErr *jetstream.APIError
+	if errors.As(err, &apiErr) {
+		if code := errorstack.CodeFromHTTPStatus(apiErr.Code); code != "" {
+			return code
+		}
+	}
+
+	return errorstack.CodeOf(err)
+}
@@ -0,0 +1,54 @@
+package nats
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testcases := []struct {
+		input    error
+		expected errorstack.Code
+	}{
+		{
+			input:    jetstream.ErrKeyNotFound,
+			expected: errorstack.CodeNotFound,
+		},
+		{
+			input:    fmt.Errorf("create: %w", jetstream.ErrKeyExists),
+			expected: errorstack.CodeConflict,
+		},
+		{
+			input:    nats.ErrNoResponders,
+			expected: errorstack.CodeUnavailable,
+		},
+		{
+			input:    nats.ErrTimeout,
+			expected: errorstack.CodeDeadlineExceeded,
+		},
+		{
+			input:    &jetstream.APIError{Code: 403, ErrorCode: 10000},
+			expected: errorstack.CodePermissionDenied,
+		},
+		{
+			input:    errors.New("unexpected"),
+			expected: errorstack.CodeUnknown,
+		},
+	}
+
+	for _, tc := range testcases {
+		err := Classify(tc.input)
+
+		assert.Equal(t, tc.expected, errorstack.CodeOf(err))
+		assert.ErrorIs(t, err, tc.input)
+	}
+
+	assert.Nil(t, Classify(nil))
+}
@@ -0,0 +1,120 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/event"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+/*
+Ensure *eventSink complies to the event.Sink type.
+*/
+var _ event.Sink = (*eventSink)(nil)
+
+/*
+eventSink is an event.Sink publishing events to NATS JetStream subjects.
+*/
+type eventSink struct {
+	js     JetStream
+	prefix string
+}
+
+/*
+NewEventSink returns an event.Sink publishing each event tracked to a subject made
+of the prefix passed and the name of the event, such as "events.subscribed" for
+the prefix "events". Characters of the name not allowed in a subject token, such
+as dots and spaces, are replaced by underscores. A stream must capture the subjects,
+such as "events.>".
+
+Messages are JSON-encoded with the event at the "event" key, so consumers can rely
+on event.EventFromJSON. The ID of the event is set as the message ID, so JetStream
+deduplicates events when they are retried.
+
+Example:
+
+	err := event.AddSink("nats", nats.NewEventSink(js, "events"))
+*/
+func NewEventSink(js JetStream, prefix string) event.Sink {
+	return &eventSink{
+		js:     js,
+		prefix: prefix,
+	}
+}
+
+/*
+Send publishes the events passed asynchronously, and waits for JetStream to
+acknowledge all of them.
+*/
+func (s *eventSink) Send(ctx context.Context, events []event.Event) error {
+	futures := make([]jetstream.PubAckFuture, 0, len(events))
+	for _, e := range events {
+		data, err := json.Marshal(map[string]event.Event{
+			event.Key: e,
+		})
+
+		if err != nil {
+			return errorstack.NewFromError(err, errorstack.WithIntegration(identifier))
+		}
+
+		msg := &Msg{
+			Subject: s.prefix + "." + subjectToken(e.Name),
+			Header:  make(nats.Header),
+			Data:    data,
+		}
+
+		msg.Header.Set(jetstream.MsgIDHeader, e.ID)
+		future, err := s.js.PublishAsync(event.ContextWithEvent(ctx, e), msg)
+		if err != nil {
+			return Classify(err)
+		}
+
+		futures = append(futures, future)
+	}
+
+	for _, future := range futures {
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			return Classify(err)
+		case <-ctx.Done():
+			return Classify(ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+/*
+subjectToken returns the name passed as a single subject token, by replacing the
+characters not allowed with underscores.
+*/
+func subjectToken(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '*' || r == '>' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+
+		return r
+	}, name)
+}
+
+/*
+enrichEvent enriches the Event found in the context passed, if any, with the
+enrichers set with event.SetEnrichers. It is called once a message is received,
+so the Event is enriched before being passed to the handler.
+*/
+func enrichEvent(ctx context.Context) context.Context {
+	e, ok := event.EventFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return event.ContextWithEvent(ctx, event.Enrich(ctx, e))
+}
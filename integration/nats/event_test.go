@@ -0,0 +1,134 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.nunchi.studio/helix/event"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+fakeJetStream is a minimal JetStream used for testing purposes, only supporting
+asynchronous publishing. Messages are acknowledged right away, unless an error is
+set.
+*/
+type fakeJetStream struct {
+	JetStream
+	msgs []*Msg
+	err  error
+}
+
+func (js *fakeJetStream) PublishAsync(ctx context.Context, msg *Msg, opts ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	js.msgs = append(js.msgs, msg)
+
+	future := &fakeFuture{
+		msg: msg,
+		ok:  make(chan *jetstream.PubAck, 1),
+		err: make(chan error, 1),
+	}
+
+	if js.err != nil {
+		future.err <- js.err
+	} else {
+		future.ok <- &jetstream.PubAck{}
+	}
+
+	return future, nil
+}
+
+/*
+fakeFuture is a jetstream.PubAckFuture already resolved.
+*/
+type fakeFuture struct {
+	msg *Msg
+	ok  chan *jetstream.PubAck
+	err chan error
+}
+
+func (f *fakeFuture) Ok() <-chan *jetstream.PubAck { return f.ok }
+func (f *fakeFuture) Err() <-chan error            { return f.err }
+func (f *fakeFuture) Msg() *nats.Msg               { return f.msg }
+
+func TestSubjectToken(t *testing.T) {
+	testcases := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "subscribed",
+			expected: "subscribed",
+		},
+		{
+			input:    "order.completed",
+			expected: "order_completed",
+		},
+		{
+			input:    "Order Completed > *",
+			expected: "Order_Completed____",
+		},
+	}
+
+	for _, tc := range testcases {
+		actual := subjectToken(tc.input)
+
+		assert.Equal(t, tc.expected, actual)
+	}
+}
+
+func TestEnrichEvent(t *testing.T) {
+	event.SetEnrichers(event.EnricherFunc(func(ctx context.Context, e *event.Event) error {
+		e.Timezone = "Europe/Paris"
+		return nil
+	}))
+
+	defer event.SetEnrichers()
+
+	ctx := enrichEvent(context.Background())
+	_, found := event.EventFromContext(ctx)
+	assert.False(t, found)
+
+	ctx = enrichEvent(event.ContextWithEvent(context.Background(), event.Event{Name: "subscribed"}))
+	actual, found := event.EventFromContext(ctx)
+	assert.True(t, found)
+	assert.Equal(t, "subscribed", actual.Name)
+	assert.Equal(t, "Europe/Paris", actual.Timezone)
+}
+
+func TestEventSink_Send(t *testing.T) {
+	events := []event.Event{
+		{
+			ID:   "evt_1",
+			Name: "order.completed",
+		},
+		{
+			ID:   "evt_2",
+			Name: "subscribed",
+		},
+	}
+
+	js := &fakeJetStream{}
+	require.NoError(t, NewEventSink(js, "events").Send(context.Background(), events))
+	require.Len(t, js.msgs, 2)
+
+	// The ID of events is set as the message ID, so JetStream deduplicates events
+	// when they are retried.
+	assert.Equal(t, "events.order_completed", js.msgs[0].Subject)
+	assert.Equal(t, "evt_1", js.msgs[0].Header.Get(jetstream.MsgIDHeader))
+	assert.Equal(t, "events.subscribed", js.msgs[1].Subject)
+	assert.Equal(t, "evt_2", js.msgs[1].Header.Get(jetstream.MsgIDHeader))
+
+	var data map[string]event.Event
+	require.NoError(t, json.Unmarshal(js.msgs[0].Data, &data))
+	assert.Equal(t, "evt_1", data[event.Key].ID)
+
+	// An error is returned if a message is not acknowledged.
+	js = &fakeJetStream{err: errors.New("no responders")}
+	assert.Error(t, NewEventSink(js, "events").Send(context.Background(), events))
+}
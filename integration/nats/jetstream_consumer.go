@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"go.nunchi.studio/helix/internal/recovery"
 	"go.nunchi.studio/helix/telemetry/trace"
 
 	"github.com/nats-io/nats.go/jetstream"
@@ -115,14 +116,22 @@ Consume can be used to continuously receive messages and handle them with the
 provided callback function
 
 The handler function passed is wrapped to automatically handles tracing and error
-recording.
+recording. A panic in the handler is recovered, recorded, and logged.
 */
 func (c *consumer) Consume(ctx context.Context, handler MsgHandler, opts ...jetstream.PullConsumeOpt) (jetstream.ConsumeContext, error) {
 	wrapped := func(msg jetstream.Msg) {
-		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(msg.Headers()))
+		ctx := otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(msg.Headers()))
+		ctx = enrichEvent(ctx)
 		ctx, span := trace.Start(ctx, trace.SpanKindConsumer, fmt.Sprintf("%s: Consumer / Consume", humanized))
 		defer span.End()
 
+		// Recover from a panic in the handler, so the consumer keeps consuming.
+		defer func() {
+			if r := recover(); r != nil {
+				recovery.Recover(ctx, r, identifier)
+			}
+		}()
+
 		setJetStreamMsgAttributes(span, msg)
 		setConsumerAttributes(span, c.config)
 		handler(ctx, msg)
@@ -39,6 +39,7 @@ func (mc *messagescontext) Next(ctx context.Context) (context.Context, jetstream
 	msg, err := mc.client.Next()
 
 	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(msg.Headers()))
+	ctx = enrichEvent(ctx)
 	ctx, span := trace.Start(ctx, trace.SpanKindConsumer, fmt.Sprintf("%s: Consumer Iterator / Message", humanized))
 	defer span.End()
 
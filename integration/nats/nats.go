@@ -1,6 +1,8 @@
 package nats
 
 import (
+	"context"
+	"crypto/tls"
 	"strings"
 
 	"go.nunchi.studio/helix/errorstack"
@@ -35,7 +37,7 @@ Config is not valid or if the connection failed.
 func Connect(cfg Config) (JetStream, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, err
 	}
@@ -52,12 +54,20 @@ func Connect(cfg Config) (JetStream, error) {
 		nats.ErrorHandler(asyncErrorHandler),
 	}
 
-	// Set TLS options only if enabled in Config.
+	// Set TLS options only if enabled in Config, resolving the secrets referenced
+	// first, if any. Stop here if error validations were encountered.
 	if cfg.TLS.Enabled {
-		opts = append(opts, nats.ClientCert(cfg.TLS.CertFile, cfg.TLS.KeyFile))
+		validations := cfg.TLS.ResolveSecrets(context.Background())
+		if len(validations) == 0 {
+			var tlsConfig *tls.Config
 
-		if len(cfg.TLS.RootCAFiles) > 0 {
-			opts = append(opts, nats.RootCAs(cfg.TLS.RootCAFiles...))
+			tlsConfig, validations = cfg.TLS.ToStandardTLS(cfg.Addresses...)
+			opts = append(opts, nats.Secure(tlsConfig))
+		}
+
+		if len(validations) > 0 {
+			stack.WithValidations(validations...)
+			return nil, stack
 		}
 	}
 
@@ -19,6 +19,10 @@ setMsgAttributes sets NATS message attributes to a trace span.
 func setMsgAttributes(span *trace.Span, msg *Msg) {
 	if msg != nil {
 		span.SetStringAttribute(fmt.Sprintf("%s.message.subject", identifier), msg.Subject)
+	}
+
+	// Messages being published have no subscription.
+	if msg != nil && msg.Sub != nil {
 		span.SetStringAttribute(fmt.Sprintf("%s.subscription.subject", identifier), msg.Sub.Subject)
 		span.SetStringAttribute(fmt.Sprintf("%s.subscription.queue", identifier), msg.Sub.Queue)
 	}
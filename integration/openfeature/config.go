@@ -2,6 +2,7 @@ package openfeature
 
 import (
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/errorstack/validate"
 )
 
 /*
@@ -12,23 +13,17 @@ type Config struct {
 	// Paths are the file paths containing the GO Feature Flag strategies.
 	//
 	// Required.
-	Paths []string `json:"paths"`
+	Paths []string `json:"paths" validate:"required"`
 }
 
 /*
-sanitize sets default values - when applicable - and validates the configuration.
+Sanitize sets default values - when applicable - and validates the configuration.
 Returns an error if configuration is not valid.
 */
-func (cfg *Config) sanitize() error {
+func (cfg *Config) Sanitize() error {
 	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
 
-	if cfg.Paths == nil || len(cfg.Paths) == 0 {
-		stack.WithValidations(errorstack.Validation{
-			Message: "Paths must be set and not be empty",
-			Path:    []string{"Config", "Paths"},
-		})
-	}
-
+	stack.WithValidations(validate.Struct(cfg, validate.WithPath("Config"), validate.WithFieldNames())...)
 	if stack.HasValidations() {
 		return stack
 	}
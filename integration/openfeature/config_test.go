@@ -36,6 +36,7 @@ func TestConfig_Sanitize(t *testing.T) {
 				Validations: []errorstack.Validation{
 					{
 						Message: "Paths must be set and not be empty",
+						Code:    "required",
 						Path:    []string{"Config", "Paths"},
 					},
 				},
@@ -54,6 +55,7 @@ func TestConfig_Sanitize(t *testing.T) {
 				Validations: []errorstack.Validation{
 					{
 						Message: "Paths must be set and not be empty",
+						Code:    "required",
 						Path:    []string{"Config", "Paths"},
 					},
 				},
@@ -62,7 +64,7 @@ func TestConfig_Sanitize(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		err := tc.before.sanitize()
+		err := tc.before.Sanitize()
 
 		assert.Equal(t, tc.before, tc.after)
 		assert.Equal(t, tc.err, err)
@@ -57,7 +57,7 @@ failed.
 func Init(cfg Config) (OpenFeature, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, err
 	}
@@ -21,17 +21,21 @@ type Config struct {
 	// Database is the database to connect to.
 	//
 	// Required.
-	Database string `json:"-"`
+	Database string `json:"-" config:"database"`
 
 	// User is the user to use to connect to the database.
 	//
 	// Required.
-	User string `json:"-"`
+	User string `json:"-" config:"user"`
 
-	// Password is the user's password to connect to the database.
+	// Password is the user's password to connect to the database. It can also
+	// reference a secret resolved when connecting, such as:
+	//
+	//   "vault://secret/data/db#password"
+	//   "file:///run/secrets/db"
 	//
 	// Required.
-	Password string `json:"-"`
+	Password string `json:"-" config:"password"`
 
 	// TLSConfig configures TLS to communicate with the PostgreSQL server.
 	TLS integration.ConfigTLS `json:"tls"`
@@ -42,10 +46,10 @@ type Config struct {
 }
 
 /*
-sanitize sets default values - when applicable - and validates the configuration.
+Sanitize sets default values - when applicable - and validates the configuration.
 Returns an error if configuration is not valid.
 */
-func (cfg *Config) sanitize() error {
+func (cfg *Config) Sanitize() error {
 	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
 
 	if cfg.Address == "" {
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"errors"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+/*
+states maps SQLSTATE codes returned by PostgreSQL to their canonical code. See
+https://www.postgresql.org/docs/current/errcodes-appendix.html.
+*/
+var states = map[string]errorstack.Code{
+	"23505": errorstack.CodeConflict,           // unique_violation
+	"23P01": errorstack.CodeConflict,           // exclusion_violation
+	"23503": errorstack.CodeFailedPrecondition, // foreign_key_violation
+	"23502": errorstack.CodeInvalidArgument,    // not_null_violation
+	"23514": errorstack.CodeInvalidArgument,    // check_violation
+	"40001": errorstack.CodeAborted,            // serialization_failure
+	"40P01": errorstack.CodeAborted,            // deadlock_detected
+	"42501": errorstack.CodePermissionDenied,   // insufficient_privilege
+	"55P03": errorstack.CodeAborted,            // lock_not_available
+	"57014": errorstack.CodeCanceled,           // query_canceled
+	"57P01": errorstack.CodeUnavailable,        // admin_shutdown
+	"57P02": errorstack.CodeUnavailable,        // crash_shutdown
+	"57P03": errorstack.CodeUnavailable,        // cannot_connect_now
+	"0A000": errorstack.CodeUnimplemented,      // feature_not_supported
+	"P0002": errorstack.CodeNotFound,           // no_data_found
+}
+
+/*
+classes maps classes of SQLSTATE codes (their first two characters) returned by
+PostgreSQL to their canonical code, when the SQLSTATE code itself is not mapped.
+*/
+var classes = map[string]errorstack.Code{
+	"08": errorstack.CodeUnavailable,        // connection_exception
+	"22": errorstack.CodeInvalidArgument,    // data_exception
+	"23": errorstack.CodeFailedPrecondition, // integrity_constraint_violation
+	"28": errorstack.CodeUnauthenticated,    // invalid_authorization_specification
+	"53": errorstack.CodeResourceExhausted,  // insufficient_resources
+	"54": errorstack.CodeResourceExhausted,  // program_limit_exceeded
+}
+
+/*
+Classify returns an error wrapping the error returned by the PostgreSQL client,
+with the canonical code matching it. The original error can still be matched with
+errors.Is and errors.As. Returns nil if the error passed is nil.
+
+Example:
+
+	row := db.QueryRow(ctx, "SELECT name FROM users WHERE id = $1", id)
+	if err := row.Scan(&name); err != nil {
+	  rest.WriteError(rw, req, postgres.Classify(err))
+	  return
+	}
+*/
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return errorstack.NewFromError(err, errorstack.WithIntegration(identifier), errorstack.WithCode(classify(err)))
+}
+
+/*
+classify returns the canonical code of the error passed.
+*/
+func classify(err error) errorstack.Code {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errorstack.CodeNotFound
+	}
+
+	if errors.Is(err, pgx.ErrTooManyRows) {
+		return errorstack.CodeFailedPrecondition
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if code, ok := states[pgErr.Code]; ok {
+			return code
+		}
+
+		if code, ok := classes[pgErr.Code[:min(2, len(pgErr.Code))]]; ok {
+			return code
+		}
+
+		return errorstack.CodeInternal
+	}
+
+	if pgconn.Timeout(err) {
+		return errorstack.CodeDeadlineExceeded
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return errorstack.CodeUnavailable
+	}
+
+	return errorstack.CodeOf(err)
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testcases := []struct {
+		input    error
+		expected errorstack.Code
+	}{
+		{
+			input:    pgx.ErrNoRows,
+			expected: errorstack.CodeNotFound,
+		},
+		{
+			input:    fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"}),
+			expected: errorstack.CodeConflict,
+		},
+		{
+			input:    &pgconn.PgError{Code: "22P02"},
+			expected: errorstack.CodeInvalidArgument,
+		},
+		{
+			input:    &pgconn.PgError{Code: "40P01"},
+			expected: errorstack.CodeAborted,
+		},
+		{
+			input:    &pgconn.PgError{Code: "XX000"},
+			expected: errorstack.CodeInternal,
+		},
+		{
+			input:    context.DeadlineExceeded,
+			expected: errorstack.CodeDeadlineExceeded,
+		},
+		{
+			input:    errors.New("unexpected"),
+			expected: errorstack.CodeUnknown,
+		},
+	}
+
+	for _, tc := range testcases {
+		err := Classify(tc.input)
+
+		assert.Equal(t, tc.expected, errorstack.CodeOf(err))
+		assert.ErrorIs(t, err, tc.input)
+	}
+
+	assert.Nil(t, Classify(nil))
+}
@@ -3,8 +3,10 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
 	"go.nunchi.studio/helix/service"
 	"go.nunchi.studio/helix/telemetry/trace"
 
@@ -45,7 +47,7 @@ error if Config is not valid or if the connection failed.
 func Connect(cfg Config) (PostgreSQL, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, err
 	}
@@ -56,9 +58,26 @@ func Connect(cfg Config) (PostgreSQL, error) {
 		config: &cfg,
 	}
 
-	// Set the default PostgreSQL options.
-	address := fmt.Sprintf("postgres://%s:%s@%s/%s", cfg.User, cfg.Password, cfg.Address, cfg.Database)
-	opts, err := pgxpool.ParseConfig(address)
+	// Resolve the secrets referenced in Config, if any. Stop here if error
+	// validations were encountered.
+	ctx := context.Background()
+	stack.WithValidations(integration.ResolveSecret(ctx, &cfg.User, "Config", "User")...)
+	stack.WithValidations(integration.ResolveSecret(ctx, &cfg.Password, "Config", "Password")...)
+	stack.WithValidations(cfg.TLS.ResolveSecrets(ctx)...)
+	if stack.HasValidations() {
+		return nil, stack
+	}
+
+	// Set the default PostgreSQL options. Credentials are escaped since secrets
+	// resolved may contain special characters.
+	address := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   cfg.Address,
+		Path:   "/" + cfg.Database,
+	}
+
+	opts, err := pgxpool.ParseConfig(address.String())
 	if err != nil {
 		stack.WithValidations(errorstack.Validation{
 			Message: normalizeErrorMessage(err),
@@ -80,14 +99,14 @@ func Connect(cfg Config) (PostgreSQL, error) {
 	if cfg.TLS.Enabled {
 		var validations []errorstack.Validation
 
-		opts.ConnConfig.TLSConfig, validations = cfg.TLS.ToStandardTLS()
+		opts.ConnConfig.TLSConfig, validations = cfg.TLS.ToStandardTLS(cfg.Address)
 		if len(validations) > 0 {
 			stack.WithValidations(validations...)
 		}
 	}
 
 	// Try to connect to the PostgreSQL servers.
-	conn.client, err = pgxpool.NewWithConfig(context.Background(), opts)
+	conn.client, err = pgxpool.NewWithConfig(ctx, opts)
 	if err != nil {
 		stack.WithValidations(errorstack.Validation{
 			Message: normalizeErrorMessage(err),
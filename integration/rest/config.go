@@ -1,7 +1,10 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
+	"net/netip"
+	"strings"
 
 	"go.nunchi.studio/helix/errorstack"
 	"go.nunchi.studio/helix/integration"
@@ -28,17 +31,44 @@ type Config struct {
 	//   GET /health
 	//
 	// It should return 200 if service is healthy, or 5xx if an error occurred.
-	// Returns 200 by default.
+	// Returns the status code of the service's health report by default. This
+	// doesn't apply to the liveness, readiness, and startup endpoints:
+	//
+	//   GET /health/live
+	//   GET /health/ready
+	//   GET /health/startup
 	Healthcheck func(req *http.Request) int `json:"-"`
 
+	// HealthErrors exposes the messages of the errors returned by the health checks
+	// of integrations in the health reports served by the liveness, readiness, and
+	// startup endpoints. Messages may contain internal details such as hosts, so
+	// they are only logged by default.
+	HealthErrors bool `json:"health_errors"`
+
 	// OpenAPI configures OpenAPI behavior within the REST API.
 	OpenAPI ConfigOpenAPI `json:"openapi"`
 
-	// TLSConfig configures TLS for the HTTP server. Only CertFile and KeyFile
-	// are took into consideration. Filenames containing a certificate and matching
-	// private key for the server must be provided. If the certificate is signed
-	// by a certificate authority, the CertFile should be the concatenation of the
-	// server's certificate, any intermediates, and the CA's certificate.
+	// Event configures the Event automatically built from HTTP requests.
+	Event ConfigEvent `json:"event"`
+
+	// DependsOn is the list of integrations the HTTP server depends on. When set,
+	// the HTTP server only starts accepting requests once these integrations have
+	// been started, and is closed before them. This allows events tracked while
+	// draining requests to be sent before closing the integrations sinks rely on.
+	//
+	// Example:
+	//
+	//   []string{"postgres", "nats"}
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// TLSConfig configures TLS for the HTTP server. ServerName, InsecureSkipVerify,
+	// and RootCAFiles are not took into consideration. Filenames containing a
+	// certificate and matching private key for the server must be provided. If the
+	// certificate is signed by a certificate authority, the CertFile should be the
+	// concatenation of the server's certificate, any intermediates, and the CA's
+	// certificate. The certificate is reloaded when rotated, without restarting the
+	// server. Clients can be authenticated with certificates by setting ClientAuth
+	// and ClientCAFiles.
 	TLS integration.ConfigTLS `json:"tls"`
 }
 
@@ -64,10 +94,33 @@ type ConfigOpenAPI struct {
 }
 
 /*
-sanitize sets default values - when applicable - and validates the configuration.
+ConfigEvent configures the Event automatically built from HTTP requests. When
+enabled, an Event is built with event.FromHTTPRequest and added to the request's
+context, so handlers only need to set its name and the details specific to the
+business logic. If an Event has already been propagated by the client, only its
+empty fields are populated. The IP and page of an Event propagated are overridden
+by the ones of the request, and its location and timezone are reset, unless the
+request comes from a trusted proxy.
+*/
+type ConfigEvent struct {
+
+	// Enabled enables the Event injection within the REST API.
+	Enabled bool `json:"enabled"`
+
+	// TrustedProxies is the list of IPs or CIDR ranges of the proxies trusted to
+	// forward the client's details through HTTP headers.
+	//
+	// Example:
+	//
+	//   []string{"10.0.0.0/8", "192.168.1.1"}
+	TrustedProxies []string `json:"trusted_proxies,omitempty"`
+}
+
+/*
+Sanitize sets default values - when applicable - and validates the configuration.
 Returns an error if configuration is not valid.
 */
-func (cfg *Config) sanitize() error {
+func (cfg *Config) Sanitize() error {
 	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
 
 	if cfg.Address == "" {
@@ -83,6 +136,25 @@ func (cfg *Config) sanitize() error {
 		}
 	}
 
+	if cfg.Event.Enabled {
+		for _, proxy := range cfg.Event.TrustedProxies {
+			if _, err := parseTrustedProxy(proxy); err != nil {
+				stack.WithValidations(errorstack.Validation{
+					Message: fmt.Sprintf("TrustedProxies must only contain valid IPs or CIDR ranges, got %q", proxy),
+					Path:    []string{"Config", "Event", "TrustedProxies"},
+				})
+			}
+		}
+	}
+
+	// Unlike clients, the HTTP server must present a certificate.
+	if cfg.TLS.Enabled && cfg.TLS.CertFile == "" && cfg.TLS.KeyFile == "" {
+		stack.WithValidations(errorstack.Validation{
+			Message: "CertFile and KeyFile must be set and not be empty",
+			Path:    []string{"Config", "TLS"},
+		})
+	}
+
 	stack.WithValidations(cfg.TLS.Sanitize()...)
 	if stack.HasValidations() {
 		return stack
@@ -90,3 +162,20 @@ func (cfg *Config) sanitize() error {
 
 	return nil
 }
+
+/*
+parseTrustedProxy parses an IP or a CIDR range to a network prefix. An IP is
+considered as a range containing only itself.
+*/
+func parseTrustedProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		return netip.ParsePrefix(proxy)
+	}
+
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
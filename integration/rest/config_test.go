@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -44,10 +45,58 @@ func TestConfig_Sanitize(t *testing.T) {
 				},
 			},
 		},
+		{
+			before: Config{
+				TLS: integration.ConfigTLS{
+					Enabled: true,
+				},
+			},
+			after: Config{
+				Address: ":8080",
+				TLS: integration.ConfigTLS{
+					Enabled: true,
+				},
+			},
+			err: &errorstack.Error{
+				Integration: identifier,
+				Message:     "Failed to validate configuration",
+				Validations: []errorstack.Validation{
+					{
+						Message: "CertFile and KeyFile must be set and not be empty",
+						Path:    []string{"Config", "TLS"},
+					},
+				},
+			},
+		},
+		{
+			before: Config{
+				Event: ConfigEvent{
+					Enabled:        true,
+					TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1", "not-an-ip"},
+				},
+			},
+			after: Config{
+				Address: ":8080",
+				Event: ConfigEvent{
+					Enabled:        true,
+					TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1", "not-an-ip"},
+				},
+			},
+			err: &errorstack.Error{
+				Integration: identifier,
+				Message:     "Failed to validate configuration",
+				Validations: []errorstack.Validation{
+					{
+						Message: `TrustedProxies must only contain valid IPs or CIDR ranges, got "not-an-ip"`,
+						Path:    []string{"Config", "Event", "TrustedProxies"},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testcases {
-		err := tc.before.sanitize()
+		err := tc.before.Sanitize()
 
 		assert.Equal(t, tc.before, tc.after)
 		assert.Equal(t, tc.err, err)
@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+
+	"go.nunchi.studio/helix/event"
+
+	"github.com/uptrace/bunrouter"
+)
+
+/*
+middlewareEvent is the HTTP middleware to build an Event from the request and add
+it to the request's context. If an Event has already been propagated by the client,
+only its empty fields are populated with the ones built from the request. The IP
+and page are always the ones built from the request, unless the request comes from
+one of the trusted proxies passed: they could be forged by any client otherwise.
+In such case, the location and timezone are also reset since they are derived from
+the IP by enrichers. The Event is then enriched by the enrichers set with
+event.SetEnrichers.
+*/
+func (r *rest) middlewareEvent(proxies []netip.Prefix) bunrouter.MiddlewareFunc {
+	return func(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+		return func(rw http.ResponseWriter, req bunrouter.Request) error {
+			built := event.FromHTTPRequest(req.Request, event.WithTrustedProxies(proxies...))
+
+			e, ok := event.EventFromContext(req.Context())
+			if !ok {
+				e = built
+			} else {
+				if !isTrustedPeer(req.Request, proxies) {
+					e.IP = built.IP
+					e.Page = built.Page
+					e.Location = event.Location{}
+					e.Timezone = ""
+				}
+
+				mergeEvent(&e, built)
+			}
+
+			e = event.Enrich(req.Context(), e)
+			ctx := event.ContextWithEvent(req.Context(), e)
+			return next(rw, req.WithContext(ctx))
+		}
+	}
+}
+
+/*
+isTrustedPeer informs if the peer that sent the HTTP request is one of the trusted
+proxies passed.
+*/
+func isTrustedPeer(req *http.Request, proxies []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range proxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+mergeEvent populates the empty fields of the Event passed with the ones built
+from the HTTP request.
+*/
+func mergeEvent(e *event.Event, built event.Event) {
+	if e.IP == nil {
+		e.IP = built.IP
+	}
+
+	if e.UserAgent == "" {
+		e.UserAgent = built.UserAgent
+	}
+
+	if e.Locale == "" {
+		e.Locale = built.Locale
+	}
+
+	if e.App == (event.App{}) {
+		e.App = built.App
+	}
+
+	if e.Campaign == (event.Campaign{}) {
+		e.Campaign = built.Campaign
+	}
+
+	if e.Device == (event.Device{}) {
+		e.Device = built.Device
+	}
+
+	if e.OS == (event.OS{}) {
+		e.OS = built.OS
+	}
+
+	if e.Page == (event.Page{}) {
+		e.Page = built.Page
+	}
+
+	if e.Referrer == (event.Referrer{}) {
+		e.Referrer = built.Referrer
+	}
+}
@@ -0,0 +1,143 @@
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.nunchi.studio/helix/event"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestMiddlewareEvent(t *testing.T) {
+	r, _ := New(Config{
+		Event: ConfigEvent{
+			Enabled:        true,
+			TrustedProxies: []string{"10.0.0.2"},
+		},
+	})
+
+	var actual event.Event
+	var found bool
+	r.GET("/pricing", func(rw http.ResponseWriter, req *http.Request) {
+		actual, found = event.EventFromContext(req.Context())
+	})
+
+	req := httptest.NewRequest("GET", "/pricing?utm_source=newsletter", nil)
+	req.RemoteAddr = "10.0.0.2:52100"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	req.Header.Set("User-Agent", "curl/8.4.0")
+
+	// Simulate an Event propagated by the client, which must be preserved.
+	name, _ := baggage.NewMember("event.name", "subscribed")
+	campaign, _ := baggage.NewMember("event.campaign.source", "ads")
+	b, _ := baggage.New(name, campaign)
+	req = req.WithContext(baggage.ContextWithBaggage(req.Context(), b))
+
+	event.SetEnrichers(event.EnricherFunc(func(ctx context.Context, e *event.Event) error {
+		e.Timezone = "Europe/Paris"
+		return nil
+	}))
+
+	defer event.SetEnrichers()
+
+	r.(http.Handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, found)
+	assert.Equal(t, "subscribed", actual.Name)
+	assert.Equal(t, net.ParseIP("198.51.100.1").To4(), actual.IP)
+	assert.Equal(t, "curl/8.4.0", actual.UserAgent)
+	assert.Equal(t, event.App{Name: "curl", Version: "8.4.0"}, actual.App)
+	assert.Equal(t, event.Campaign{Source: "ads"}, actual.Campaign)
+	assert.Equal(t, "/pricing", actual.Page.Path)
+	assert.Equal(t, "Europe/Paris", actual.Timezone)
+}
+
+func TestMiddlewareEvent_Spoofed(t *testing.T) {
+	testcases := []struct {
+		remote string
+		ip     string
+		path   string
+		city   string
+	}{
+		{
+			remote: "203.0.113.7:52100",
+			ip:     "203.0.113.7",
+			path:   "/pricing",
+		},
+		{
+			remote: "10.0.0.2:52100",
+			ip:     "192.0.2.1",
+			path:   "/admin",
+			city:   "Paris",
+		},
+	}
+
+	r, _ := New(Config{
+		Event: ConfigEvent{
+			Enabled:        true,
+			TrustedProxies: []string{"10.0.0.2"},
+		},
+	})
+
+	var actual event.Event
+	r.GET("/pricing", func(rw http.ResponseWriter, req *http.Request) {
+		actual, _ = event.EventFromContext(req.Context())
+	})
+
+	for _, tc := range testcases {
+		req := httptest.NewRequest("GET", "/pricing", nil)
+		req.RemoteAddr = tc.remote
+
+		// The IP, page, and location propagated are only kept if the peer is a
+		// trusted proxy.
+		name, _ := baggage.NewMember("event.name", "subscribed")
+		ip, _ := baggage.NewMember("event.ip", "192.0.2.1")
+		path, _ := baggage.NewMember("event.page.path", "/admin")
+		city, _ := baggage.NewMember("event.location.city", "Paris")
+		b, _ := baggage.New(name, ip, path, city)
+		req = req.WithContext(baggage.ContextWithBaggage(req.Context(), b))
+
+		r.(http.Handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "subscribed", actual.Name)
+		assert.Equal(t, tc.ip, actual.IP.String())
+		assert.Equal(t, tc.path, actual.Page.Path)
+		assert.Equal(t, tc.city, actual.Location.City)
+	}
+}
+
+func TestMiddlewareEvent_Enforcement(t *testing.T) {
+	event.SetEnforcement(event.EnforcementDrop)
+	defer event.SetEnforcement(event.EnforcementNone)
+
+	r, _ := New(Config{
+		Event: ConfigEvent{
+			Enabled: true,
+		},
+	})
+
+	var actual event.Event
+	var found bool
+	r.GET("/pricing", func(rw http.ResponseWriter, req *http.Request) {
+		e, ok := event.EventFromContext(req.Context())
+		assert.True(t, ok)
+
+		// The Event built from the request is enforced once named by the handler.
+		e.Name = "subscribed"
+		ctx := event.ContextWithEvent(req.Context(), e)
+		actual, found = event.EventFromContext(ctx)
+	})
+
+	req := httptest.NewRequest("GET", "/pricing", nil)
+	req.RemoteAddr = "203.0.113.7:52100"
+	r.(http.Handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, found)
+	assert.Equal(t, "subscribed", actual.Name)
+	assert.Equal(t, "203.0.113.7", actual.IP.String())
+}
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"context"
+	"net"
 	"net/http"
 
 	"go.nunchi.studio/helix/errorstack"
@@ -16,6 +17,16 @@ Ensure *rest complies to the integration.Integration type.
 */
 var _ integration.Integration = (*rest)(nil)
 
+/*
+Ensure *rest complies to the integration.Dependent type.
+*/
+var _ integration.Dependent = (*rest)(nil)
+
+/*
+Ensure *rest complies to the integration.Readiness type.
+*/
+var _ integration.Readiness = (*rest)(nil)
+
 /*
 String returns the string representation of the HTTP REST integration.
 */
@@ -24,7 +35,44 @@ func (r *rest) String() string {
 }
 
 /*
-Start starts the HTTP server of the HTTP REST integration.
+DependsOn returns the integrations the HTTP REST integration depends on, as set
+in Config.
+*/
+func (r *rest) DependsOn() []string {
+	return r.config.DependsOn
+}
+
+/*
+Ready returns a channel closed once the HTTP server accepts connections.
+*/
+func (r *rest) Ready(ctx context.Context) <-chan struct{} {
+	r.readyMutex.Lock()
+	defer r.readyMutex.Unlock()
+
+	return r.ready
+}
+
+/*
+setReady closes the ready channel if the HTTP server is ready, or recreates it if
+the HTTP server is not ready anymore, so the integration can be started again.
+*/
+func (r *rest) setReady(ready bool) {
+	r.readyMutex.Lock()
+	defer r.readyMutex.Unlock()
+
+	switch {
+	case ready && !r.isReady:
+		close(r.ready)
+	case !ready && r.isReady:
+		r.ready = make(chan struct{})
+	}
+
+	r.isReady = ready
+}
+
+/*
+Start starts the HTTP server of the HTTP REST integration. The integration is
+ready as soon as the server listens on the address set in Config.
 */
 func (r *rest) Start(ctx context.Context) error {
 	stack := errorstack.New("Failed to start HTTP server", errorstack.WithIntegration(identifier))
@@ -35,6 +83,10 @@ func (r *rest) Start(ctx context.Context) error {
 		h = r.config.Middleware(r.bun)
 	}
 
+	// Recover from panics in handlers and middlewares, so the HTTP server returns
+	// an error to the client instead of aborting the connection.
+	h = middlewareRecovery(h)
+
 	// Wrap the handler previously built with the one designed for OpenTelemetry
 	// traces.
 	h = otelhttp.NewHandler(h, cloudprovider.Detected.Service(),
@@ -43,17 +95,31 @@ func (r *rest) Start(ctx context.Context) error {
 
 	// Create the HTTP server with the given configuration and the handler built.
 	r.server = &http.Server{
-		Addr:    r.config.Address,
-		Handler: h,
+		Addr:      r.config.Address,
+		Handler:   h,
+		TLSConfig: r.tls,
 	}
 
+	// Listen on the address first, so the integration can inform it is ready to
+	// accept connections.
+	listener, err := net.Listen("tcp", r.server.Addr)
+	if err != nil {
+		stack.WithValidations(errorstack.Validation{
+			Message: err.Error(),
+		})
+
+		return stack
+	}
+
+	r.setReady(true)
+
 	// Start the HTTP server with or without TLS depending on the Config, and catch
-	// unexpected errors.
-	var err error
+	// unexpected errors. The HTTP server is not ready anymore once stopped.
+	defer r.setReady(false)
 	if r.config.TLS.Enabled {
-		err = r.server.ListenAndServeTLS(r.config.TLS.CertFile, r.config.TLS.KeyFile)
+		err = r.server.ServeTLS(listener, "", "")
 	} else {
-		err = r.server.ListenAndServe()
+		err = r.server.Serve(listener)
 	}
 
 	if err != nil && err != http.ErrServerClosed {
@@ -1,7 +1,12 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+
+	"go.nunchi.studio/helix/errorstack"
 
 	"golang.org/x/text/language"
 )
@@ -31,8 +36,32 @@ var supportedLocales = map[language.Tag]map[int]string{
 		http.StatusConflict:              "Failed to process target resource because of conflict",
 		http.StatusRequestEntityTooLarge: "Can not process payload too large",
 		http.StatusTooManyRequests:       "Request-rate limit has been reached",
+		statusClientClosedRequest:        "Request has been canceled by the client",
 		http.StatusInternalServerError:   "We have been notified of this unexpected internal error",
+		http.StatusNotImplemented:        "Resource does not support this operation",
 		http.StatusServiceUnavailable:    "Please try again in a few moments",
+		http.StatusGatewayTimeout:        "Request took too long to process, please try again",
+	},
+}
+
+/*
+supportedValidations represents the messages handled by each language for the
+given code of validation failures. Messages can contain the params of the
+validation failure between braces.
+*/
+var supportedValidations = map[language.Tag]map[string]string{
+	language.English: {
+		"required":   "Value must be set",
+		"type":       "Value must be of type {type}",
+		"enum":       "Value must be one of {values}",
+		"format":     "Value must be formatted as {format}",
+		"pattern":    "Value must match pattern {pattern}",
+		"min_length": "Value must be at least {min} characters long",
+		"max_length": "Value must be at most {max} characters long",
+		"minimum":    "Value must be greater than or equal to {min}",
+		"maximum":    "Value must be less than or equal to {max}",
+		"min_items":  "Value must contain at least {min} items",
+		"max_items":  "Value must contain at most {max} items",
 	},
 }
 
@@ -51,8 +80,13 @@ Supported status code:
   - [http.StatusConflict]
   - [http.StatusRequestEntityTooLarge]
   - [http.StatusTooManyRequests]
+  - 499 (Client Closed Request)
   - [http.StatusInternalServerError]
+  - [http.StatusNotImplemented]
   - [http.StatusServiceUnavailable]
+  - [http.StatusGatewayTimeout]
+
+Status codes 499, 501, and 504 are only written by WriteError.
 
 Example:
 
@@ -71,25 +105,114 @@ Example:
 	})
 */
 func AddOrEditLanguage(lang language.Tag, locales map[int]string) {
-	var exists bool = false
-	for found := range supportedLocales {
-		if lang == found {
-			exists = true
-			break
-		}
+	addLanguage(lang)
+
+	// Go through each locale passed to only update the one desired and not override
+	// all others.
+	for status, msg := range locales {
+		supportedLocales[lang][status] = msg
 	}
+}
+
+/*
+AddOrEditValidations allows a service to add or edit a language support for
+validation messages in the HTTP REST API, based on the code of the validation
+failure. Languages are shared with AddOrEditLanguage. Messages can contain the
+params of the validation failure between braces.
+
+Supported validation codes for requests validated against the OpenAPI description:
+
+  - "required"
+  - "type" (with "type" param)
+  - "enum" (with "values" param)
+  - "format" (with "format" param)
+  - "pattern" (with "pattern" param)
+  - "min_length" (with "min" param)
+  - "max_length" (with "max" param)
+  - "minimum" (with "min" param)
+  - "maximum" (with "max" param)
+  - "min_items" (with "min" param)
+  - "max_items" (with "max" param)
+
+Services can also add messages for the codes of their own validation failures.
+
+Example:
+
+	rest.AddOrEditValidations(language.French, map[string]string{
+		"required":   "<locale>",
+		"min_length": "<locale with {min}>",
+	})
+*/
+func AddOrEditValidations(lang language.Tag, messages map[string]string) {
+	addLanguage(lang)
+
+	for code, msg := range messages {
+		supportedValidations[lang][code] = msg
+	}
+}
 
-	// Create a new language if it doesn't already exist.
-	if !exists {
+/*
+addLanguage adds a language to the supported ones, if it doesn't already exist.
+*/
+func addLanguage(lang language.Tag) {
+	if _, exists := supportedLocales[lang]; !exists {
 		supportedLocales[lang] = make(map[int]string)
 		supportedLanguages = append(supportedLanguages, lang)
 	}
 
-	// Go through each locale passed to only update the one desired and not override
-	// all others.
-	for status, msg := range locales {
-		supportedLocales[lang][status] = msg
+	if _, exists := supportedValidations[lang]; !exists {
+		supportedValidations[lang] = make(map[string]string)
+	}
+}
+
+/*
+localize returns the default error message of the status code passed, in the
+preferred language of the client. It falls back to English if the language has no
+message for the status code, and to the message of a 500 otherwise.
+*/
+func localize(req *http.Request, status int) string {
+	if msg, ok := supportedLocales[getPreferredLanguage(req)][status]; ok {
+		return msg
+	}
+
+	if msg, ok := supportedLocales[language.English][status]; ok {
+		return msg
+	}
+
+	return supportedLocales[language.English][http.StatusInternalServerError]
+}
+
+/*
+localizeValidations returns the validation failures passed with their message
+rendered in the preferred language of the client, given their code and params.
+It falls back to English if the language has no message for a code. Validation
+failures with no code, or with a code not supported, are left as-is.
+*/
+func localizeValidations(req *http.Request, validations []errorstack.Validation) []errorstack.Validation {
+	lang := getPreferredLanguage(req)
+	localized := slices.Clone(validations)
+	for i, validation := range localized {
+		if validation.Code == "" {
+			continue
+		}
+
+		msg, ok := supportedValidations[lang][validation.Code]
+		if !ok {
+			msg, ok = supportedValidations[language.English][validation.Code]
+		}
+
+		if !ok {
+			continue
+		}
+
+		for key, value := range validation.Params {
+			msg = strings.ReplaceAll(msg, "{"+key+"}", fmt.Sprint(value))
+		}
+
+		localized[i].Message = msg
 	}
+
+	return localized
 }
 
 /*
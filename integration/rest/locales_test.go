@@ -2,7 +2,12 @@ package rest
 
 import (
 	"net/http"
+	"testing"
 
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
 	"golang.org/x/text/language"
 )
 
@@ -24,3 +29,86 @@ func addFrenchLocalesForTest() {
 		http.StatusServiceUnavailable:    "Veuillez réessayer dans quelques instants",
 	})
 }
+
+func TestLocalizeValidations(t *testing.T) {
+	AddOrEditValidations(language.French, map[string]string{
+		"min_length": "La valeur doit contenir au moins {min} caractères",
+	})
+
+	reqWithLang, _ := http.NewRequest(http.MethodPost, "/anything", nil)
+	reqWithLang.Header.Add("Accept-Language", "fr")
+
+	validations := []errorstack.Validation{
+		{
+			Message: "minimum string length is 3",
+			Code:    "min_length",
+			Params:  map[string]any{"min": 3},
+			Path:    []string{"request", "body", "name"},
+		},
+		{
+			Message: "property \"email\" is missing",
+			Code:    "required",
+			Path:    []string{"request", "body", "email"},
+		},
+		{
+			Message: "Email is already registered",
+			Code:    "custom",
+		},
+		{
+			Message: "Failed to validate request",
+		},
+	}
+
+	testcases := []struct {
+		req      *http.Request
+		expected []string
+	}{
+		{
+			req: nil,
+			expected: []string{
+				"Value must be at least 3 characters long",
+				"Value must be set",
+				"Email is already registered",
+				"Failed to validate request",
+			},
+		},
+		{
+			req: reqWithLang,
+			expected: []string{
+				"La valeur doit contenir au moins 3 caractères",
+				"Value must be set",
+				"Email is already registered",
+				"Failed to validate request",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		localized := localizeValidations(tc.req, validations)
+
+		for i, validation := range localized {
+			assert.Equal(t, tc.expected[i], validation.Message)
+			assert.Equal(t, validations[i].Path, validation.Path)
+		}
+	}
+
+	// Validations passed must not be modified.
+	assert.Equal(t, "minimum string length is 3", validations[0].Message)
+}
+
+func TestConvertSchemaError(t *testing.T) {
+	maxLength := uint64(5)
+	schema := &openapi3.Schema{
+		MinLength: 3,
+		MaxLength: &maxLength,
+	}
+
+	var schemaErr *openapi3.SchemaError
+	assert.ErrorAs(t, schema.VisitJSON("ab"), &schemaErr)
+	assert.Equal(t, "min_length", convertSchemaError(schemaErr).Code)
+	assert.Equal(t, map[string]any{"min": uint64(3)}, convertSchemaError(schemaErr).Params)
+
+	assert.ErrorAs(t, schema.VisitJSON("abcdef"), &schemaErr)
+	assert.Equal(t, "max_length", convertSchemaError(schemaErr).Code)
+	assert.Equal(t, map[string]any{"max": uint64(5)}, convertSchemaError(schemaErr).Params)
+}
@@ -114,8 +114,8 @@ func (r *rest) middlewareValidation(next bunrouter.HandlerFunc) bunrouter.Handle
 		err = openapi3filter.ValidateRequest(ctx, in)
 		if err != nil {
 			res := &Response{
-				Status: "Bad Request",
-				Error:  errorstack.New("Failed to validate request"),
+				Status: http.StatusText(http.StatusBadRequest),
+				Error:  errorstack.New(localize(req.Request, http.StatusBadRequest), errorstack.WithCode(errorstack.CodeInvalidArgument)),
 			}
 
 			// Convert the default error to match the errorsstack.Error format. Add
@@ -130,14 +130,13 @@ func (r *rest) middlewareValidation(next bunrouter.HandlerFunc) bunrouter.Handle
 
 				sort.Strings(names)
 				for _, k := range names {
-					msgs := issues[k]
-					for _, msg := range msgs {
-						res.Error.Validations = append(res.Error.Validations, errorstack.Validation{
-							Message: msg,
-							Path:    strings.Split(k, "."),
-						})
+					for _, validation := range issues[k] {
+						validation.Path = strings.Split(k, ".")
+						res.Error.Validations = append(res.Error.Validations, validation)
 					}
 				}
+
+				res.Error.Validations = localizeValidations(req.Request, res.Error.Validations)
 			}
 
 			spanReq.RecordError("failed to validate request", err)
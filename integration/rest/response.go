@@ -71,14 +71,15 @@ type Response struct {
 
 /*
 handlerHealthcheck is the default handler function for the healthcheck endpoint.
-Call the custom function defined in the Config if applicable.
+Call the custom function defined in the Config if applicable. Otherwise it relies
+on the health report of the service.
 */
 func (r *rest) handlerHealthcheck(rw http.ResponseWriter, req bunrouter.Request) error {
 	var status int = http.StatusOK
 	if r.config.Healthcheck != nil {
 		status = r.config.Healthcheck(req.Request)
 	} else {
-		status, _ = service.Status(req.Context())
+		status = service.Health(req.Context()).Code
 	}
 
 	res := &Response{
@@ -92,6 +93,61 @@ func (r *rest) handlerHealthcheck(rw http.ResponseWriter, req bunrouter.Request)
 	return nil
 }
 
+/*
+handlerLiveness is the default handler function for the liveness endpoint. It
+returns `200` as long as the service is able to answer, along the health report
+of the service.
+*/
+func (r *rest) handlerLiveness(rw http.ResponseWriter, req bunrouter.Request) error {
+	report := service.Health(req.Context())
+	r.writeHealth(rw, report.Liveness(), report)
+
+	return nil
+}
+
+/*
+handlerReadiness is the default handler function for the readiness endpoint. It
+returns `200` once the service and all its integrations are ready and no critical
+integration is unhealthy, `503` otherwise, along the health report of the service.
+*/
+func (r *rest) handlerReadiness(rw http.ResponseWriter, req bunrouter.Request) error {
+	report := service.Health(req.Context())
+	r.writeHealth(rw, report.Readiness(), report)
+
+	return nil
+}
+
+/*
+handlerStartup is the default handler function for the startup endpoint. It
+returns `200` once the service and all its integrations are ready, `503` otherwise,
+along the health report of the service.
+*/
+func (r *rest) handlerStartup(rw http.ResponseWriter, req bunrouter.Request) error {
+	report := service.Health(req.Context())
+	r.writeHealth(rw, report.Startup(), report)
+
+	return nil
+}
+
+/*
+writeHealth writes the health report of the service in the response's data, with
+the status code passed. Error messages are redacted unless exposed in Config.
+*/
+func (r *rest) writeHealth(rw http.ResponseWriter, status int, report *service.HealthReport) {
+	if !r.config.HealthErrors {
+		report = report.Redacted()
+	}
+
+	res := &Response{
+		Status: http.StatusText(status),
+		Data:   report,
+	}
+
+	b, _ := json.Marshal(res)
+	rw.WriteHeader(status)
+	rw.Write(b)
+}
+
 /*
 handlerNotFound is the default handler function if the path is not found (error
 404).
@@ -1,7 +1,10 @@
 package rest
 
 import (
+	"context"
+	"crypto/tls"
 	"net/http"
+	"sync"
 
 	"go.nunchi.studio/helix/errorstack"
 	"go.nunchi.studio/helix/service"
@@ -40,9 +43,23 @@ type rest struct {
 	// server is the standard http.Server used to serve HTTP requests.
 	server *http.Server
 
+	// tls is the TLS configuration of the HTTP server, built from Config if TLS
+	// is enabled.
+	tls *tls.Config
+
 	// oapirouter is the OpenAPI router used to validate requests and responses
 	// against the OpenAPI description passed in Config.
 	oapirouter routers.Router
+
+	// readyMutex allows to lock/unlock access to the ready channel, since it is
+	// recreated each time the HTTP server stops.
+	readyMutex sync.Mutex
+
+	// ready is closed once the HTTP server accepts connections.
+	ready chan struct{}
+
+	// isReady informs if the ready channel is closed.
+	isReady bool
 }
 
 /*
@@ -52,7 +69,7 @@ or OpenAPI description are not valid.
 func New(cfg Config) (REST, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +78,7 @@ func New(cfg Config) (REST, error) {
 	stack := errorstack.New("Failed to initialize integration", errorstack.WithIntegration(identifier))
 	r := &rest{
 		config: &cfg,
+		ready:  make(chan struct{}),
 	}
 
 	var validations []errorstack.Validation
@@ -69,6 +87,19 @@ func New(cfg Config) (REST, error) {
 		stack.WithValidations(validations...)
 	}
 
+	// Build the TLS configuration only if enabled in Config, resolving the secrets
+	// referenced first, if any.
+	if cfg.TLS.Enabled {
+		validations = cfg.TLS.ResolveSecrets(context.Background())
+		if len(validations) == 0 {
+			r.tls, validations = cfg.TLS.ToStandardServerTLS()
+		}
+
+		if len(validations) > 0 {
+			stack.WithValidations(validations...)
+		}
+	}
+
 	// Only try to build the OpenAPI router if enabled in Config.
 	if cfg.OpenAPI.Enabled {
 		r.oapirouter, validations = r.buildRouterOpenAPI()
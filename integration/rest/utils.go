@@ -2,12 +2,15 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"strings"
 
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/internal/recovery"
 	"go.nunchi.studio/helix/telemetry/log"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -28,6 +31,10 @@ func writeResponseOnError[T any](status int, rw http.ResponseWriter, res *Respon
 		opt(res)
 	}
 
+	if res.Error != nil {
+		res.Error.Validations = localizeValidations(req, res.Error.Validations)
+	}
+
 	writeResponse[T](status, rw, res, req)
 }
 
@@ -62,9 +69,31 @@ func writeResponse[T any](status int, rw http.ResponseWriter, res *Response, req
 	rw.Write(b)
 }
 
+/*
+middlewareRecovery recovers from a panic in the handler passed. The panic is
+recorded and logged, and a 500 status code is written to the HTTP response writer.
+*/
+func middlewareRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				recovery.Recover(req.Context(), rec, identifier)
+				WriteEmptyInternalServerError(rw, req)
+			}
+		}()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
 /*
 buildRouter tries to build the HTTP router. It comes with opinionated handlers
-for 404 and 405 HTTP errors, as well as for the health endpoint.
+for 404 and 405 HTTP errors, as well as for the health, liveness, readiness, and
+startup endpoints.
 */
 func (r *rest) buildRouter() (*bunrouter.CompatRouter, []errorstack.Validation) {
 	opts := []bunrouter.Option{
@@ -80,12 +109,27 @@ func (r *rest) buildRouter() (*bunrouter.CompatRouter, []errorstack.Validation)
 		bunrouter.WithMethodNotAllowedHandler(r.handlerMethodNotAllowed),
 	}
 
+	// Build the Event from requests only if enabled in Config, so it is available
+	// to the OpenAPI middleware and handlers.
+	if r.config.Event.Enabled {
+		var proxies []netip.Prefix
+		for _, proxy := range r.config.Event.TrustedProxies {
+			prefix, _ := parseTrustedProxy(proxy)
+			proxies = append(proxies, prefix)
+		}
+
+		opts = append(opts, bunrouter.WithMiddleware(r.middlewareEvent(proxies)))
+	}
+
 	if r.config.OpenAPI.Enabled {
 		opts = append(opts, bunrouter.WithMiddleware(r.middlewareValidation))
 	}
 
 	router := bunrouter.New(opts...).Compat()
 	router.Router.GET("/health", r.handlerHealthcheck)
+	router.Router.GET("/health/live", r.handlerLiveness)
+	router.Router.GET("/health/ready", r.handlerReadiness)
+	router.Router.GET("/health/startup", r.handlerStartup)
 
 	return router, nil
 }
@@ -148,17 +192,18 @@ func isValidUrl(link string) (*url.URL, bool) {
 
 /*
 convertError converts errors encountered by OpenAPI validation to a map of issues
-so we can more easily format them in the response returned to the client.
+so we can more easily format them in the response returned to the client. Paths
+of the validation failures are the keys of the map.
 
 Example:
 
-	map[string][]string{
-	  "request.headers.X-API-KEY": ["security requirements must be set"]
-	  "request.body.event.name": ["property "name" is missing"]
+	map[string][]errorstack.Validation{
+	  "request.headers.X-API-KEY": [{Message: "security requirements must be set", Code: "required"}]
+	  "request.body.event.name": [{Message: "property "name" is missing", Code: "required"}]
 	}
 */
-func convertError(prefix string, me openapi3.MultiError) map[string][]string {
-	issues := make(map[string][]string)
+func convertError(prefix string, me openapi3.MultiError) map[string][]errorstack.Validation {
+	issues := make(map[string][]errorstack.Validation)
 
 	for _, err := range me {
 		switch err := err.(type) {
@@ -170,18 +215,31 @@ func convertError(prefix string, me openapi3.MultiError) map[string][]string {
 				field = fmt.Sprintf("%s.%s", field, strings.Join(path, "."))
 			}
 
-			issues[field] = append(issues[field], err.Error())
+			issues[field] = append(issues[field], convertSchemaError(err))
 
 		// Check global security requirements errors.
 		case *openapi3filter.SecurityRequirementsError:
 			field := fmt.Sprintf("%s.%s", "request.headers", strings.TrimPrefix(err.Error(), "security requirements failed: "))
-			issues[field] = append(issues[field], "security requirements must be set")
+			issues[field] = append(issues[field], errorstack.Validation{
+				Message: "security requirements must be set",
+				Code:    "required",
+			})
 
 		// Check request schema error.
 		case *openapi3filter.RequestError:
 			if err.Parameter != nil {
 				field := fmt.Sprintf("%s.%s", err.Parameter.In, err.Parameter.Name)
-				issues[field] = append(issues[field], err.Error())
+				validation := errorstack.Validation{
+					Message: err.Error(),
+				}
+
+				var schemaErr *openapi3.SchemaError
+				if errors.As(err.Err, &schemaErr) {
+					converted := convertSchemaError(schemaErr)
+					validation.Code, validation.Params = converted.Code, converted.Params
+				}
+
+				issues[field] = append(issues[field], validation)
 				continue
 			}
 
@@ -194,16 +252,100 @@ func convertError(prefix string, me openapi3.MultiError) map[string][]string {
 			}
 
 			if err.RequestBody != nil {
-				issues[prefix] = append(issues[prefix], err.Error())
+				issues[prefix] = append(issues[prefix], errorstack.Validation{
+					Message: err.Error(),
+				})
+
 				continue
 			}
 
 		// Make sure to handle every usecases. Even though this should never happen.
 		default:
 			field := "unknown"
-			issues[field] = append(issues[field], err.Error())
+			issues[field] = append(issues[field], errorstack.Validation{
+				Message: err.Error(),
+			})
 		}
 	}
 
 	return issues
 }
+
+/*
+convertSchemaError converts a schema error encountered by OpenAPI validation to a
+validation failure, with the code and params of the schema's field that failed so
+the message can be translated. The message is the reason of the error as-is if the
+field is not supported.
+*/
+func convertSchemaError(err *openapi3.SchemaError) errorstack.Validation {
+	validation := errorstack.Validation{
+		Message: err.Error(),
+	}
+
+	schema := err.Schema
+	if schema == nil {
+		return validation
+	}
+
+	switch err.SchemaField {
+	case "required":
+		validation.Code = "required"
+
+	case "type":
+		if schema.Type != nil {
+			validation.Code = "type"
+			validation.Params = map[string]any{"type": strings.Join(schema.Type.Slice(), ", ")}
+		}
+
+	case "enum":
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = fmt.Sprint(value)
+		}
+
+		validation.Code = "enum"
+		validation.Params = map[string]any{"values": strings.Join(values, ", ")}
+
+	case "format":
+		validation.Code = "format"
+		validation.Params = map[string]any{"format": schema.Format}
+
+	case "pattern":
+		validation.Code = "pattern"
+		validation.Params = map[string]any{"pattern": schema.Pattern}
+
+	case "minLength":
+		validation.Code = "min_length"
+		validation.Params = map[string]any{"min": schema.MinLength}
+
+	case "maxLength":
+		if schema.MaxLength != nil {
+			validation.Code = "max_length"
+			validation.Params = map[string]any{"max": *schema.MaxLength}
+		}
+
+	case "minimum":
+		if schema.Min != nil {
+			validation.Code = "minimum"
+			validation.Params = map[string]any{"min": *schema.Min}
+		}
+
+	case "maximum":
+		if schema.Max != nil {
+			validation.Code = "maximum"
+			validation.Params = map[string]any{"max": *schema.Max}
+		}
+
+	case "minItems":
+		validation.Code = "min_items"
+		validation.Params = map[string]any{"min": schema.MinItems}
+
+	case "maxItems":
+		if schema.MaxItems != nil {
+			validation.Code = "max_items"
+			validation.Params = map[string]any{"max": *schema.MaxItems}
+		}
+	}
+
+	return validation
+}
@@ -16,7 +16,7 @@ Default error message (in English) is:
 func WriteBadRequest[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusBadRequest),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusBadRequest]),
+		Error:  errorstack.New(localize(req, http.StatusBadRequest)),
 	}
 
 	for _, opt := range opts {
@@ -43,7 +43,7 @@ Default error message (in English) is:
 func WriteUnauthorized[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusUnauthorized),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusUnauthorized]),
+		Error:  errorstack.New(localize(req, http.StatusUnauthorized)),
 	}
 
 	for _, opt := range opts {
@@ -70,7 +70,7 @@ Default error message (in English) is:
 func WritePaymentRequired[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusPaymentRequired),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusPaymentRequired]),
+		Error:  errorstack.New(localize(req, http.StatusPaymentRequired)),
 	}
 
 	for _, opt := range opts {
@@ -97,7 +97,7 @@ Default error message (in English) is:
 func WriteForbidden[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusForbidden),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusForbidden]),
+		Error:  errorstack.New(localize(req, http.StatusForbidden)),
 	}
 
 	for _, opt := range opts {
@@ -124,7 +124,7 @@ Default error message (in English) is:
 func WriteNotFound[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusNotFound),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusNotFound]),
+		Error:  errorstack.New(localize(req, http.StatusNotFound)),
 	}
 
 	for _, opt := range opts {
@@ -151,7 +151,7 @@ Default error message (in English) is:
 func WriteMethodNotAllowed[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusMethodNotAllowed),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusMethodNotAllowed]),
+		Error:  errorstack.New(localize(req, http.StatusMethodNotAllowed)),
 	}
 
 	for _, opt := range opts {
@@ -178,7 +178,7 @@ Default error message (in English) is:
 func WriteConflict[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusConflict),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusConflict]),
+		Error:  errorstack.New(localize(req, http.StatusConflict)),
 	}
 
 	for _, opt := range opts {
@@ -206,7 +206,7 @@ Default error message (in English) is:
 func WriteRequestEntityTooLarge[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusRequestEntityTooLarge),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusRequestEntityTooLarge]),
+		Error:  errorstack.New(localize(req, http.StatusRequestEntityTooLarge)),
 	}
 
 	for _, opt := range opts {
@@ -234,7 +234,7 @@ Default error message (in English) is:
 func WriteTooManyRequests[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusTooManyRequests),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusTooManyRequests]),
+		Error:  errorstack.New(localize(req, http.StatusTooManyRequests)),
 	}
 
 	for _, opt := range opts {
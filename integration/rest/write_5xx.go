@@ -17,7 +17,7 @@ Default error message (in English) is:
 func WriteInternalServerError[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusInternalServerError),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusInternalServerError]),
+		Error:  errorstack.New(localize(req, http.StatusInternalServerError)),
 	}
 
 	for _, opt := range opts {
@@ -45,7 +45,7 @@ Default error message (in English) is:
 func WriteServiceUnavailable[T any](rw http.ResponseWriter, req *http.Request, opts ...WithOnError) {
 	res := &Response{
 		Status: http.StatusText(http.StatusServiceUnavailable),
-		Error:  errorstack.New(supportedLocales[getPreferredLanguage(req)][http.StatusServiceUnavailable]),
+		Error:  errorstack.New(localize(req, http.StatusServiceUnavailable)),
 	}
 
 	for _, opt := range opts {
@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+statusClientClosedRequest is the non-standard status code written when the client
+canceled the request, as popularized by nginx.
+*/
+const statusClientClosedRequest = 499
+
+/*
+WriteError writes the status code and body matching the error passed to the HTTP
+response writer. The status code is the one of the error's code, found anywhere
+in the tree of the error with errorstack.CodeOf. Errors without code are written
+as 500. This works best with errors returned by the Classify function of the
+integrations:
+
+	if err := row.Scan(&user); err != nil {
+	  rest.WriteError(rw, req, postgres.Classify(err))
+	  return
+	}
+
+The error message is the default one of the status code, so internal messages are
+never exposed to clients. The code is always exposed. Validations of the first
+*errorstack.Error found in the tree are exposed for 4xx status codes only. Both
+can be overridden with WithOnError options.
+*/
+func WriteError(rw http.ResponseWriter, req *http.Request, err error, opts ...WithOnError) {
+	code := errorstack.CodeOf(err)
+	status := code.HTTPStatus()
+
+	res := &Response{
+		Status: statusText(status),
+		Error:  errorstack.New(localize(req, status), errorstack.WithCode(code)),
+	}
+
+	var stack *errorstack.Error
+	if status < http.StatusInternalServerError && errors.As(err, &stack) {
+		res.Error.Validations = append(res.Error.Validations, stack.Validations...)
+	}
+
+	writeResponseOnError[struct{}](status, rw, res, req, opts...)
+}
+
+/*
+statusText returns the text of the status code passed, including the non-standard
+ones written by this package.
+*/
+func statusText(status int) string {
+	if status == statusClientClosedRequest {
+		return "Client Closed Request"
+	}
+
+	return http.StatusText(status)
+}
@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteError(t *testing.T) {
+	addFrenchLocalesForTest()
+	reqWithLang, _ := http.NewRequest(http.MethodPost, "/anything", nil)
+	reqWithLang.Header.Add("Accept-Language", "fr")
+
+	invalid := errorstack.New("Failed to validate user", errorstack.WithCode(errorstack.CodeInvalidArgument))
+	invalid.WithValidations(errorstack.Validation{
+		Message: "Email must be set",
+		Path:    []string{"request", "body", "email"},
+	})
+
+	internal := errorstack.New("Failed to query database", errorstack.WithCode(errorstack.CodeInternal))
+	internal.WithValidations(errorstack.Validation{
+		Message: "Connection reset by peer",
+	})
+
+	testcases := []struct {
+		req            *http.Request
+		err            error
+		withOnError    []WithOnError
+		expectedStatus int
+		expected       Response
+	}{
+		{
+			req:            nil,
+			err:            fmt.Errorf("wrapped: %w", errorstack.NewFromError(errors.New("no rows in result set"), errorstack.WithCode(errorstack.CodeNotFound))),
+			expectedStatus: http.StatusNotFound,
+			expected: Response{
+				Status: http.StatusText(http.StatusNotFound),
+				Error: &errorstack.Error{
+					Code:    errorstack.CodeNotFound,
+					Message: "Resource does not exist",
+				},
+			},
+		},
+		{
+			req:            reqWithLang,
+			err:            invalid,
+			expectedStatus: http.StatusBadRequest,
+			expected: Response{
+				Status: http.StatusText(http.StatusBadRequest),
+				Error: &errorstack.Error{
+					Code:    errorstack.CodeInvalidArgument,
+					Message: "Échec de la validation de la requête",
+					Validations: []errorstack.Validation{
+						{
+							Message: "Email must be set",
+							Path:    []string{"request", "body", "email"},
+						},
+					},
+				},
+			},
+		},
+		{
+			req:            nil,
+			err:            internal,
+			expectedStatus: http.StatusInternalServerError,
+			expected: Response{
+				Status: http.StatusText(http.StatusInternalServerError),
+				Error: &errorstack.Error{
+					Code:    errorstack.CodeInternal,
+					Message: "We have been notified of this unexpected internal error",
+				},
+			},
+		},
+		{
+			req:            reqWithLang,
+			err:            context.DeadlineExceeded,
+			expectedStatus: http.StatusGatewayTimeout,
+			expected: Response{
+				Status: http.StatusText(http.StatusGatewayTimeout),
+				Error: &errorstack.Error{
+					Code:    errorstack.CodeDeadlineExceeded,
+					Message: "Request took too long to process, please try again",
+				},
+			},
+		},
+		{
+			req: nil,
+			err: errors.New("connection reset by peer"),
+			withOnError: []WithOnError{
+				WithMetadataOnError(map[string]string{
+					"anything": "value",
+				}),
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expected: Response{
+				Status: http.StatusText(http.StatusInternalServerError),
+				Error: &errorstack.Error{
+					Code:    errorstack.CodeUnknown,
+					Message: "We have been notified of this unexpected internal error",
+				},
+				Metadata: map[string]string{
+					"anything": "value",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		rw := httptest.NewRecorder()
+		WriteError(rw, tc.req, tc.err, tc.withOnError...)
+
+		assert.Equal(t, tc.expectedStatus, rw.Code)
+
+		expected, _ := json.Marshal(tc.expected)
+		actual := rw.Body.Bytes()
+		assert.JSONEq(t, string(expected), string(actual))
+	}
+}
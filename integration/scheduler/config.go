@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+Config is used to configure the scheduler integration.
+*/
+type Config struct {
+
+	// Location is the name of the time zone used for evaluating cron expressions,
+	// as expected by time.LoadLocation.
+	//
+	// Default:
+	//
+	//   "UTC"
+	Location string `json:"location,omitempty"`
+
+	// DependsOn is the list of integrations — by name — the scheduler depends on.
+	// The scheduler is started once they are ready, and closed before them. This
+	// is useful when jobs rely on other integrations, such as a database.
+	//
+	// Example:
+	//
+	//   []string{"postgres"}
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	// location is the time zone loaded from Location.
+	location *time.Location
+}
+
+/*
+Sanitize sets default values - when applicable - and validates the configuration.
+Returns an error if configuration is not valid.
+*/
+func (cfg *Config) Sanitize() error {
+	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
+
+	if cfg.Location == "" {
+		cfg.Location = "UTC"
+	}
+
+	location, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		stack.WithValidations(errorstack.Validation{
+			Message: err.Error(),
+			Path:    []string{"Config", "Location"},
+		})
+	}
+
+	cfg.location = location
+	if stack.HasValidations() {
+		return stack
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Sanitize(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	testcases := []struct {
+		before Config
+		after  Config
+		err    error
+	}{
+		{
+			before: Config{},
+			after: Config{
+				Location: "UTC",
+				location: time.UTC,
+			},
+			err: nil,
+		},
+		{
+			before: Config{
+				Location:  "Europe/Paris",
+				DependsOn: []string{"postgres"},
+			},
+			after: Config{
+				Location:  "Europe/Paris",
+				DependsOn: []string{"postgres"},
+				location:  paris,
+			},
+			err: nil,
+		},
+		{
+			before: Config{
+				Location: "Nowhere/Unknown",
+			},
+			after: Config{
+				Location: "Nowhere/Unknown",
+			},
+			err: &errorstack.Error{
+				Integration: identifier,
+				Message:     "Failed to validate configuration",
+				Validations: []errorstack.Validation{
+					{
+						Message: "unknown time zone Nowhere/Unknown",
+						Path:    []string{"Config", "Location"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		err := tc.before.Sanitize()
+
+		assert.Equal(t, tc.before, tc.after)
+		assert.Equal(t, tc.err, err)
+	}
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
+)
+
+/*
+Ensure *connection complies to the integration.Integration type.
+*/
+var _ integration.Integration = (*connection)(nil)
+
+/*
+Ensure *connection complies to the integration.Dependent type.
+*/
+var _ integration.Dependent = (*connection)(nil)
+
+/*
+String returns the string representation of the scheduler integration.
+*/
+func (conn *connection) String() string {
+	return identifier
+}
+
+/*
+DependsOn returns the integrations the scheduler depends on, as set in Config.
+*/
+func (conn *connection) DependsOn() []string {
+	return conn.config.DependsOn
+}
+
+/*
+Start starts scheduling the jobs in the background. It doesn't block.
+*/
+func (conn *connection) Start(ctx context.Context) error {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.gen != nil {
+		stack := errorstack.New("Failed to start scheduler", errorstack.WithIntegration(identifier))
+		stack.WithValidations(errorstack.Validation{
+			Message: "Scheduler has already been started",
+		})
+
+		return stack
+	}
+
+	// Runs must not be canceled when the context passed is done, but only once
+	// the scheduler is closed.
+	gen := &generation{
+		stop: make(chan struct{}),
+	}
+
+	gen.ctx, gen.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	for _, e := range conn.entries {
+		gen.wg.Add(1)
+		go conn.loop(e, gen)
+	}
+
+	conn.gen = gen
+	return nil
+}
+
+/*
+Close stops scheduling new runs, and waits for in-flight runs to complete. If the
+context is done before, the context passed to in-flight runs is canceled and an
+error listing the jobs still running is returned. The scheduler can then be
+started again, even if these runs have not returned yet.
+*/
+func (conn *connection) Close(ctx context.Context) error {
+	conn.mutex.Lock()
+	gen := conn.gen
+	if gen == nil {
+		conn.mutex.Unlock()
+		return nil
+	}
+
+	close(gen.stop)
+	conn.gen = nil
+	conn.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		gen.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		gen.cancel()
+		return nil
+	case <-ctx.Done():
+		gen.cancel()
+	}
+
+	stack := errorstack.New("Failed to gracefully close scheduler", errorstack.WithIntegration(identifier))
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	names := make([]string, 0, len(conn.entries))
+	for name := range conn.entries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		e := conn.entries[name]
+		e.mutex.Lock()
+		if e.running > 0 {
+			stack.WithValidations(errorstack.Validation{
+				Message: fmt.Sprintf("Job %q is still running", name),
+			})
+		}
+
+		e.mutex.Unlock()
+	}
+
+	return stack
+}
+
+/*
+Status returns `200` if the scheduler is started, `503` otherwise.
+*/
+func (conn *connection) Status(ctx context.Context) (int, error) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if conn.gen == nil {
+		return 503, nil
+	}
+
+	return 200, nil
+}
@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/robfig/cron"
+)
+
+/*
+Job is a named unit of work run periodically by the scheduler, either on an
+interval or on a cron expression.
+*/
+type Job struct {
+
+	// Name is the unique name of the job within the scheduler. It is used for
+	// naming the trace spans of each run.
+	//
+	// Required.
+	Name string `json:"name"`
+
+	// Interval is the duration between two runs of the job. Either Interval or
+	// Cron must be set.
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// Cron is the cron expression — with five fields or a descriptor such as
+	// "@hourly" — defining when the job runs. It is evaluated in the time zone set
+	// in Config. Either Interval or Cron must be set.
+	//
+	// Example:
+	//
+	//   "*/15 * * * *"
+	Cron string `json:"cron,omitempty"`
+
+	// Jitter is the maximum random delay added before each run. This avoids the
+	// same job running at the exact same time across replicas of a service.
+	Jitter time.Duration `json:"jitter,omitempty"`
+
+	// AllowOverlap allows a run to start even if the previous one is still running.
+	// By default, a run is skipped if the previous one is not completed.
+	AllowOverlap bool `json:"allow_overlap"`
+
+	// Timeout is the maximum duration of a run. The context passed to Run is done
+	// once the timeout is reached. No timeout is applied if 0.
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Run is the function executed on each run of the job.
+	//
+	// Required.
+	Run func(ctx context.Context) error `json:"-"`
+}
+
+/*
+schedule is the interface a job's schedule must respect, for computing the time
+of the next run.
+*/
+type schedule interface {
+	Next(t time.Time) time.Time
+}
+
+/*
+interval is a schedule running a job every duration.
+*/
+type interval time.Duration
+
+/*
+Next returns the time of the next run after t.
+*/
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+/*
+entry is a job scheduled within the scheduler, along its runtime state.
+*/
+type entry struct {
+	job      Job
+	schedule schedule
+
+	// mutex allows to lock/unlock access to running.
+	mutex sync.Mutex
+
+	// running is the number of runs currently in-flight.
+	running int
+}
+
+/*
+sanitize validates the job, and returns its schedule. Returns an error if the job
+is not valid.
+*/
+func (job *Job) sanitize() (schedule, error) {
+	stack := errorstack.New("Failed to validate job", errorstack.WithIntegration(identifier))
+
+	if job.Name == "" {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Name must be set and not be empty",
+			Path:    []string{"Job", "Name"},
+		})
+	}
+
+	if job.Run == nil {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Run must be set and not be nil",
+			Path:    []string{"Job", "Run"},
+		})
+	}
+
+	var sched schedule
+	switch {
+	case job.Interval == 0 && job.Cron == "":
+		stack.WithValidations(errorstack.Validation{
+			Message: "Either Interval or Cron must be set",
+			Path:    []string{"Job"},
+		})
+
+	case job.Interval != 0 && job.Cron != "":
+		stack.WithValidations(errorstack.Validation{
+			Message: "Interval and Cron must not be set at the same time",
+			Path:    []string{"Job"},
+		})
+
+	case job.Interval < 0:
+		stack.WithValidations(errorstack.Validation{
+			Message: "Interval must be greater than 0",
+			Path:    []string{"Job", "Interval"},
+		})
+
+	case job.Interval > 0:
+		sched = interval(job.Interval)
+
+	default:
+		parsed, err := cron.ParseStandard(job.Cron)
+		if err != nil {
+			stack.WithValidations(errorstack.Validation{
+				Message: err.Error(),
+				Path:    []string{"Job", "Cron"},
+			})
+
+			break
+		}
+
+		// A valid expression can still never match, such as on February 30th. No
+		// next run is found in such case.
+		if parsed.Next(time.Now()).IsZero() {
+			stack.WithValidations(errorstack.Validation{
+				Message: fmt.Sprintf("Cron must match at least one time, got %q", job.Cron),
+				Path:    []string{"Job", "Cron"},
+			})
+
+			break
+		}
+
+		sched = parsed
+	}
+
+	if job.Jitter < 0 {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Jitter must be greater than or equal to 0",
+			Path:    []string{"Job", "Jitter"},
+		})
+	}
+
+	if job.Timeout < 0 {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Timeout must be greater than or equal to 0",
+			Path:    []string{"Job", "Timeout"},
+		})
+	}
+
+	if stack.HasValidations() {
+		return nil, stack
+	}
+
+	return sched, nil
+}
@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJob_Sanitize(t *testing.T) {
+	run := func(ctx context.Context) error {
+		return nil
+	}
+
+	testcases := []struct {
+		job Job
+		err error
+	}{
+		{
+			job: Job{
+				Name:     "refresh",
+				Interval: time.Minute,
+				Run:      run,
+			},
+			err: nil,
+		},
+		{
+			job: Job{
+				Name: "refresh",
+				Cron: "*/15 * * * *",
+				Run:  run,
+			},
+			err: nil,
+		},
+		{
+			job: Job{
+				Cron:   "@hourly",
+				Jitter: -time.Second,
+			},
+			err: &errorstack.Error{
+				Integration: identifier,
+				Message:     "Failed to validate job",
+				Validations: []errorstack.Validation{
+					{
+						Message: "Name must be set and not be empty",
+						Path:    []string{"Job", "Name"},
+					},
+					{
+						Message: "Run must be set and not be nil",
+						Path:    []string{"Job", "Run"},
+					},
+					{
+						Message: "Jitter must be greater than or equal to 0",
+						Path:    []string{"Job", "Jitter"},
+					},
+				},
+			},
+		},
+		{
+			job: Job{
+				Name:     "refresh",
+				Interval: time.Minute,
+				Cron:     "@hourly",
+				Run:      run,
+			},
+			err: &errorstack.Error{
+				Integration: identifier,
+				Message:     "Failed to validate job",
+				Validations: []errorstack.Validation{
+					{
+						Message: "Interval and Cron must not be set at the same time",
+						Path:    []string{"Job"},
+					},
+				},
+			},
+		},
+		{
+			job: Job{
+				Name: "refresh",
+				Cron: "* * *",
+				Run:  run,
+			},
+			err: &errorstack.Error{
+				Integration: identifier,
+				Message:     "Failed to validate job",
+				Validations: []errorstack.Validation{
+					{
+						Message: "Expected exactly 5 fields, found 3: * * *",
+						Path:    []string{"Job", "Cron"},
+					},
+				},
+			},
+		},
+		{
+			job: Job{
+				Name: "refresh",
+				Cron: "0 0 30 2 *",
+				Run:  run,
+			},
+			err: &errorstack.Error{
+				Integration: identifier,
+				Message:     "Failed to validate job",
+				Validations: []errorstack.Validation{
+					{
+						Message: `Cron must match at least one time, got "0 0 30 2 *"`,
+						Path:    []string{"Job", "Cron"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		_, err := tc.job.sanitize()
+
+		assert.Equal(t, tc.err, err)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	conn := &connection{
+		config:  &Config{location: time.UTC},
+		entries: make(map[string]*entry),
+	}
+
+	runs := make(chan struct{}, 10)
+	err := conn.Schedule(Job{
+		Name:     "refresh",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs <- struct{}{}
+			panic("recovered")
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Error(t, conn.Schedule(Job{Name: "refresh", Interval: time.Second, Run: func(ctx context.Context) error { return nil }}))
+
+	assert.NoError(t, conn.Start(context.Background()))
+	<-runs
+	<-runs
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, conn.Close(ctx))
+	status, _ := conn.Status(ctx)
+	assert.Equal(t, 503, status)
+}
+
+/*
+never is a schedule with no next run.
+*/
+type never struct{}
+
+func (never) Next(t time.Time) time.Time {
+	return time.Time{}
+}
+
+func TestScheduler_Never(t *testing.T) {
+	conn := &connection{
+		config: &Config{location: time.UTC},
+	}
+
+	gen := &generation{
+		stop: make(chan struct{}),
+		ctx:  context.Background(),
+	}
+
+	// The loop stops right away instead of spinning when there is no next run.
+	stopped := make(chan struct{})
+	gen.wg.Add(1)
+	go func() {
+		conn.loop(&entry{job: Job{Name: "refresh"}, schedule: never{}}, gen)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected the loop to stop when there is no next run")
+	}
+}
+
+func TestScheduler_Restart(t *testing.T) {
+	conn := &connection{
+		config:  &Config{location: time.UTC},
+		entries: make(map[string]*entry),
+	}
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	err := conn.Schedule(Job{
+		Name:     "refresh",
+		Interval: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+
+			<-release
+			return nil
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, conn.Start(context.Background()))
+	<-started
+
+	// The run ignores its context, so it is still in-flight once the scheduler
+	// is closed.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Error(t, conn.Close(ctx))
+
+	// Starting again must not share state with the previous generation, which
+	// is still waiting for its run to return.
+	assert.NoError(t, conn.Start(context.Background()))
+	close(release)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, conn.Close(ctx))
+}
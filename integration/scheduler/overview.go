@@ -0,0 +1,15 @@
+/*
+Package scheduler exposes an opinionated way to run periodic jobs, on intervals
+or cron expressions, within the lifecycle of a helix service.
+*/
+package scheduler
+
+/*
+identifier represents the integration's unique identifier.
+*/
+const identifier = "scheduler"
+
+/*
+humanized represents the integration's humanized name.
+*/
+const humanized = "Scheduler"
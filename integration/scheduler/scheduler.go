@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/internal/recovery"
+	"go.nunchi.studio/helix/service"
+	"go.nunchi.studio/helix/telemetry/log"
+	"go.nunchi.studio/helix/telemetry/trace"
+)
+
+/*
+Scheduler exposes an opinionated way to run periodic jobs, by bringing automatic
+distributed tracing as well as error recording within traces.
+*/
+type Scheduler interface {
+	Schedule(job Job) error
+}
+
+/*
+connection represents the scheduler integration. It respects the
+integration.Integration and Scheduler interfaces.
+*/
+type connection struct {
+
+	// config holds the Config initially passed when creating a new scheduler.
+	config *Config
+
+	// mutex allows to lock/unlock access to the jobs and the state of the
+	// scheduler.
+	mutex sync.Mutex
+
+	// entries holds the jobs scheduled, by name.
+	entries map[string]*entry
+
+	// gen is the generation of the scheduler currently started. It is nil if the
+	// scheduler is not started.
+	gen *generation
+}
+
+/*
+generation holds the state of the scheduler between a Start and a Close. Each
+Start creates a new generation, so loops and runs of a previous one that are
+still running when closing the scheduler never share state with the next one.
+*/
+type generation struct {
+
+	// stop is closed when closing the scheduler, so no new run is started.
+	stop chan struct{}
+
+	// ctx is the context passed to runs. It is only canceled if in-flight runs
+	// are not completed before the scheduler is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// wg waits for the scheduling loops and in-flight runs.
+	wg sync.WaitGroup
+}
+
+/*
+New tries to create a new scheduler given the Config. Returns an error if Config
+is not valid or if the initialization failed.
+*/
+func New(cfg Config) (Scheduler, error) {
+
+	// No need to continue if Config is not valid.
+	err := cfg.Sanitize()
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &connection{
+		config:  &cfg,
+		entries: make(map[string]*entry),
+	}
+
+	// Try to attach the integration to the service.
+	if err := service.Attach(conn); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+/*
+Schedule adds a job to the scheduler. Jobs can be scheduled before or after the
+service is started. Returns an error if the job is not valid or if a job with the
+same name has already been scheduled.
+*/
+func (conn *connection) Schedule(job Job) error {
+	sched, err := job.sanitize()
+	if err != nil {
+		return err
+	}
+
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	if _, exists := conn.entries[job.Name]; exists {
+		stack := errorstack.New("Failed to schedule job", errorstack.WithIntegration(identifier))
+		stack.WithValidations(errorstack.Validation{
+			Message: fmt.Sprintf("Job %q has already been scheduled", job.Name),
+			Path:    []string{"Job", "Name"},
+		})
+
+		return stack
+	}
+
+	e := &entry{
+		job:      job,
+		schedule: sched,
+	}
+
+	conn.entries[job.Name] = e
+	if conn.gen != nil {
+		conn.gen.wg.Add(1)
+		go conn.loop(e, conn.gen)
+	}
+
+	return nil
+}
+
+/*
+loop schedules the runs of a job until the stop channel of the generation is
+closed.
+*/
+func (conn *connection) loop(e *entry, gen *generation) {
+	defer gen.wg.Done()
+
+	for {
+		now := time.Now().In(conn.config.location)
+		next := e.schedule.Next(now)
+		if next.IsZero() {
+			log.Error(gen.ctx, fmt.Sprintf("Job %q has no next run, stopping its schedule", e.job.Name))
+			return
+		}
+
+		wait := next.Sub(now)
+		if e.job.Jitter > 0 {
+			wait += rand.N(e.job.Jitter)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-gen.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Skip the run if the previous one is still running and overlaps are not
+		// allowed.
+		e.mutex.Lock()
+		if e.running > 0 && !e.job.AllowOverlap {
+			e.mutex.Unlock()
+			log.Warn(gen.ctx, fmt.Sprintf("Job %q is still running, skipping run", e.job.Name))
+			continue
+		}
+
+		e.running++
+		e.mutex.Unlock()
+
+		gen.wg.Add(1)
+		go conn.run(e, gen)
+	}
+}
+
+/*
+run executes a single run of a job, within its own trace span. A panic in the job
+is recovered and recorded as an error.
+*/
+func (conn *connection) run(e *entry, gen *generation) {
+	defer gen.wg.Done()
+	defer func() {
+		e.mutex.Lock()
+		e.running--
+		e.mutex.Unlock()
+	}()
+
+	ctx, span := trace.Start(gen.ctx, trace.SpanKindInternal, fmt.Sprintf("%s: %s", humanized, e.job.Name))
+	defer span.End()
+
+	span.SetStringAttribute(fmt.Sprintf("%s.job.name", identifier), e.job.Name)
+	if e.job.Cron != "" {
+		span.SetStringAttribute(fmt.Sprintf("%s.job.cron", identifier), e.job.Cron)
+	} else {
+		span.SetIntAttribute(fmt.Sprintf("%s.job.interval_ms", identifier), e.job.Interval.Milliseconds())
+	}
+
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+
+	// A panic is already recorded and logged when recovered.
+	recovered, err := execute(ctx, e.job)
+	if err != nil && !recovered {
+		span.RecordError("failed to run job", err)
+		log.Error(ctx, fmt.Sprintf("Job %q failed", e.job.Name), err)
+	}
+}
+
+/*
+execute executes the Run function of a job, and converts a panic into an error.
+The panic is recorded and logged, and the service applies its panic policy.
+Returns if the error has been recovered from a panic.
+*/
+func execute(ctx context.Context, job Job) (recovered bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			recovered, err = true, recovery.Recover(ctx, r, identifier)
+		}
+	}()
+
+	return false, job.Run(ctx)
+}
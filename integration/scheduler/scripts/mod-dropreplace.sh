@@ -0,0 +1,4 @@
+#!/usr/bin/env bash
+
+go mod edit \
+  -dropreplace go.nunchi.studio/helix
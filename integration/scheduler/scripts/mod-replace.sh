@@ -0,0 +1,4 @@
+#!/usr/bin/env bash
+
+go mod edit \
+  -replace go.nunchi.studio/helix=../../
@@ -0,0 +1,4 @@
+#!/usr/bin/env bash
+
+go mod edit \
+  -require go.nunchi.studio/helix@$1
@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+SecretResolver resolves a secret given its reference, without the scheme. For
+example, the resolver registered for the "vault" scheme receives:
+
+	"secret/data/db#password"
+
+When the reference is:
+
+	"vault://secret/data/db#password"
+*/
+type SecretResolver func(ctx context.Context, reference string) (string, error)
+
+/*
+secretTimeout is the maximum duration for resolving a secret, since integrations
+resolve secrets when initialized, usually with no deadline.
+*/
+const secretTimeout = 10 * time.Second
+
+/*
+resolvers holds the secret resolvers registered, by scheme.
+*/
+var resolvers = struct {
+	mutex    sync.RWMutex
+	byScheme map[string]SecretResolver
+}{
+	byScheme: map[string]SecretResolver{
+		"env":  resolveFromEnv,
+		"file": resolveFromFile,
+	},
+}
+
+/*
+RegisterSecretResolver registers a resolver for the scheme passed, such as "vault".
+It overrides the resolver previously registered for the scheme, if any. Resolvers
+for the "env" and "file" schemes are registered by default:
+
+	"env://PG_PASSWORD"
+	"file:///run/secrets/pg"
+*/
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	resolvers.mutex.Lock()
+	defer resolvers.mutex.Unlock()
+
+	resolvers.byScheme[scheme] = resolver
+}
+
+/*
+ResolveSecret resolves the value passed in place if it references a secret, using
+the resolver registered for the scheme of the reference. The value is left as-is
+if it doesn't start with a scheme, such as "vault://". A validation is returned if
+no resolver is registered for the scheme, so a reference is never used as the
+secret itself. The resolution must complete within 10 seconds. This doesn't return
+a standard error since this function shall only be called by integrations. This
+allows to easily add error validations to an existing errorstack:
+
+	stack.WithValidations(integration.ResolveSecret(ctx, &cfg.Password, "Config", "Password")...)
+*/
+func ResolveSecret(ctx context.Context, value *string, path ...string) []errorstack.Validation {
+	var validations []errorstack.Validation
+
+	scheme, reference, found := strings.Cut(*value, "://")
+	if !found || !isScheme(scheme) {
+		return validations
+	}
+
+	resolvers.mutex.RLock()
+	resolver, exists := resolvers.byScheme[scheme]
+	resolvers.mutex.RUnlock()
+
+	if !exists {
+		validations = append(validations, errorstack.Validation{
+			Message: fmt.Sprintf("Failed to resolve secret: no resolver registered for %q", scheme),
+			Path:    path,
+		})
+
+		return validations
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, secretTimeout)
+	defer cancel()
+
+	secret, err := resolver(ctx, reference)
+	if err != nil {
+		validations = append(validations, errorstack.Validation{
+			Message: fmt.Sprintf("Failed to resolve secret from %q: %v", scheme, err),
+			Path:    path,
+		})
+
+		return validations
+	}
+
+	*value = secret
+	return validations
+}
+
+/*
+isScheme informs if the string passed is a valid URI scheme: a letter followed by
+letters, digits, "+", "-", or ".". This avoids to consider a plain value containing
+"://" as a reference to a secret.
+*/
+func isScheme(s string) bool {
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+/*
+resolveFromEnv resolves a secret from the environment variable referenced.
+*/
+func resolveFromEnv(ctx context.Context, reference string) (string, error) {
+	secret, exists := os.LookupEnv(reference)
+	if !exists {
+		return "", fmt.Errorf("environment variable %q is not set", reference)
+	}
+
+	return secret, nil
+}
+
+/*
+resolveFromFile resolves a secret from the content of the file referenced, with
+trailing line breaks removed.
+*/
+func resolveFromFile(ctx context.Context, reference string) (string, error) {
+	b, err := os.ReadFile(reference)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSecret(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+
+	t.Setenv("HELIX_TEST_SECRET", "from-env")
+	RegisterSecretResolver("test", func(ctx context.Context, reference string) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return "", errors.New("context has no deadline")
+		}
+
+		if reference != "secret/data/db#password" {
+			return "", errors.New("secret not found")
+		}
+
+		return "from-test", nil
+	})
+
+	testcases := []struct {
+		value       string
+		expected    string
+		validations []errorstack.Validation
+	}{
+		{
+			value:    "plain",
+			expected: "plain",
+		},
+		{
+			value:    "unknown://secret",
+			expected: "unknown://secret",
+			validations: []errorstack.Validation{
+				{
+					Message: `Failed to resolve secret: no resolver registered for "unknown"`,
+					Path:    []string{"Config", "Password"},
+				},
+			},
+		},
+		{
+			value:    "p@ss://word",
+			expected: "p@ss://word",
+		},
+		{
+			value:    "env://HELIX_TEST_SECRET",
+			expected: "from-env",
+		},
+		{
+			value:    "file://" + file,
+			expected: "from-file",
+		},
+		{
+			value:    "test://secret/data/db#password",
+			expected: "from-test",
+		},
+		{
+			value:    "env://HELIX_TEST_MISSING",
+			expected: "env://HELIX_TEST_MISSING",
+			validations: []errorstack.Validation{
+				{
+					Message: `Failed to resolve secret from "env": environment variable "HELIX_TEST_MISSING" is not set`,
+					Path:    []string{"Config", "Password"},
+				},
+			},
+		},
+		{
+			value:    "test://secret/data/cache#password",
+			expected: "test://secret/data/cache#password",
+			validations: []errorstack.Validation{
+				{
+					Message: `Failed to resolve secret from "test": secret not found`,
+					Path:    []string{"Config", "Password"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		value := tc.value
+		validations := ResolveSecret(context.Background(), &value, "Config", "Password")
+
+		assert.Equal(t, tc.expected, value)
+		assert.Equal(t, tc.validations, validations)
+	}
+}
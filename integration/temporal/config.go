@@ -2,6 +2,7 @@ package temporal
 
 import (
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/errorstack/validate"
 	"go.nunchi.studio/helix/integration"
 
 	"go.temporal.io/sdk/converter"
@@ -34,6 +35,15 @@ type Config struct {
 	// for Temporal.
 	Worker ConfigWorker `json:"worker"`
 
+	// DependsOn is the list of integrations the Temporal integration depends on.
+	// When set, the Temporal worker only starts once these integrations have been
+	// started, and is stopped before them.
+	//
+	// Example:
+	//
+	//   []string{"postgres", "vault"}
+	DependsOn []string `json:"depends_on,omitempty"`
+
 	// TLSConfig configures TLS to communicate with the Temporal server.
 	TLS integration.ConfigTLS `json:"tls"`
 }
@@ -85,10 +95,10 @@ type ConfigWorker struct {
 }
 
 /*
-sanitize sets default values - when applicable - and validates the configuration.
+Sanitize sets default values - when applicable - and validates the configuration.
 Returns an error if configuration is not valid.
 */
-func (cfg *Config) sanitize() error {
+func (cfg *Config) Sanitize() error {
 	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
 
 	if cfg.Address == "" {
@@ -99,15 +109,12 @@ func (cfg *Config) sanitize() error {
 		cfg.Namespace = "default"
 	}
 
+	v := validate.New("Config")
 	if cfg.Worker.Enabled {
-		if cfg.Worker.TaskQueue == "" {
-			stack.WithValidations(errorstack.Validation{
-				Message: "TaskQueue must be set and not be empty",
-				Path:    []string{"Config", "Worker", "TaskQueue"},
-			})
-		}
+		v.Nested("Worker").Field("TaskQueue", cfg.Worker.TaskQueue).Required()
 	}
 
+	stack.WithValidations(v.Validations()...)
 	stack.WithValidations(cfg.TLS.Sanitize()...)
 	if stack.HasValidations() {
 		return stack
@@ -51,6 +51,7 @@ func TestConfig_Sanitize(t *testing.T) {
 				Validations: []errorstack.Validation{
 					{
 						Message: "TaskQueue must be set and not be empty",
+						Code:    "required",
 						Path:    []string{"Config", "Worker", "TaskQueue"},
 					},
 				},
@@ -59,7 +60,7 @@ func TestConfig_Sanitize(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		err := tc.before.sanitize()
+		err := tc.before.Sanitize()
 
 		assert.Equal(t, tc.before, tc.after)
 		assert.Equal(t, tc.err, err)
@@ -0,0 +1,53 @@
+package temporal
+
+import (
+	"errors"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/temporal"
+)
+
+/*
+Classify returns an error wrapping the error returned by the Temporal client, with
+the canonical code matching it. The original error can still be matched with
+errors.Is and errors.As. Returns nil if the error passed is nil.
+
+Example:
+
+	run, err := client.ExecuteWorkflow(ctx, opts, "workflow")
+	if err != nil {
+	  rest.WriteError(rw, req, temporal.Classify(err))
+	  return
+	}
+*/
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return errorstack.NewFromError(err, errorstack.WithIntegration(identifier), errorstack.WithCode(classify(err)))
+}
+
+/*
+classify returns the canonical code of the error passed. Errors returned by the
+Temporal server are classified given their gRPC status code.
+*/
+func classify(err error) errorstack.Code {
+	var svcErr serviceerror.ServiceError
+	if errors.As(err, &svcErr) {
+		if code := errorstack.CodeFromGRPCCode(uint32(svcErr.Status().Code())); code != "" {
+			return code
+		}
+	}
+
+	switch {
+	case temporal.IsCanceledError(err):
+		return errorstack.CodeCanceled
+	case temporal.IsTimeoutError(err):
+		return errorstack.CodeDeadlineExceeded
+	}
+
+	return errorstack.CodeOf(err)
+}
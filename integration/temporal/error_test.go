@@ -0,0 +1,45 @@
+package temporal
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/api/serviceerror"
+)
+
+func TestClassify(t *testing.T) {
+	testcases := []struct {
+		input    error
+		expected errorstack.Code
+	}{
+		{
+			input:    serviceerror.NewNotFound("workflow not found"),
+			expected: errorstack.CodeNotFound,
+		},
+		{
+			input:    fmt.Errorf("start: %w", serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "")),
+			expected: errorstack.CodeAlreadyExists,
+		},
+		{
+			input:    serviceerror.NewResourceExhausted(0, "rate limit exceeded"),
+			expected: errorstack.CodeResourceExhausted,
+		},
+		{
+			input:    errors.New("unexpected"),
+			expected: errorstack.CodeUnknown,
+		},
+	}
+
+	for _, tc := range testcases {
+		err := Classify(tc.input)
+
+		assert.Equal(t, tc.expected, errorstack.CodeOf(err))
+		assert.ErrorIs(t, err, tc.input)
+	}
+
+	assert.Nil(t, Classify(nil))
+}
@@ -7,7 +7,6 @@ import (
 	"go.nunchi.studio/helix/integration"
 
 	"go.temporal.io/sdk/client"
-	"go.temporal.io/sdk/worker"
 )
 
 /*
@@ -15,6 +14,16 @@ Ensure *connection complies to the integration.Integration type.
 */
 var _ integration.Integration = (*connection)(nil)
 
+/*
+Ensure *connection complies to the integration.Dependent type.
+*/
+var _ integration.Dependent = (*connection)(nil)
+
+/*
+Ensure *connection complies to the integration.Readiness type.
+*/
+var _ integration.Readiness = (*connection)(nil)
+
 /*
 String returns the string representation of the Temporal integration.
 */
@@ -23,13 +32,51 @@ func (conn *connection) String() string {
 }
 
 /*
-Start starts the Temporal worker, if applicable.
+DependsOn returns the integrations the Temporal integration depends on, as set
+in Config.
+*/
+func (conn *connection) DependsOn() []string {
+	return conn.config.DependsOn
+}
+
+/*
+Ready returns a channel closed once the Temporal worker is started, or right away
+when starting the integration if no worker is enabled.
+*/
+func (conn *connection) Ready(ctx context.Context) <-chan struct{} {
+	conn.readyMutex.Lock()
+	defer conn.readyMutex.Unlock()
+
+	return conn.ready
+}
+
+/*
+setReady closes the ready channel if the integration is ready, or recreates it if
+the integration is not ready anymore, so the integration can be started again.
+*/
+func (conn *connection) setReady(ready bool) {
+	conn.readyMutex.Lock()
+	defer conn.readyMutex.Unlock()
+
+	switch {
+	case ready && !conn.isReady:
+		close(conn.ready)
+	case !ready && conn.isReady:
+		conn.ready = make(chan struct{})
+	}
+
+	conn.isReady = ready
+}
+
+/*
+Start starts the Temporal worker, if applicable. The worker runs in the background
+until the integration is closed.
 */
 func (conn *connection) Start(ctx context.Context) error {
 	stack := errorstack.New("Failed to start worker", errorstack.WithIntegration(identifier))
 
 	if conn.worker != nil {
-		err := conn.worker.Run(worker.InterruptCh())
+		err := conn.worker.Start()
 		if err != nil {
 			stack.WithValidations(errorstack.Validation{
 				Message: err.Error(),
@@ -39,6 +86,7 @@ func (conn *connection) Start(ctx context.Context) error {
 		}
 	}
 
+	conn.setReady(true)
 	return nil
 }
 
@@ -52,6 +100,7 @@ func (conn *connection) Close(ctx context.Context) error {
 	}
 
 	conn.client.Close()
+	conn.setReady(false)
 	return nil
 }
 
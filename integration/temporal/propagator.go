@@ -29,6 +29,11 @@ func (p *custompropagator) Inject(ctx context.Context, hw workflow.HeaderWriter)
 		return nil
 	}
 
+	// Enrich the Event before it is sent to workflows, since they can not enrich
+	// it themselves in a deterministic way. Enrichers only populate empty fields,
+	// so an Event already enriched is left untouched.
+	e = event.Enrich(ctx, e)
+
 	// Retrieve the current span, and set Event's attributes.
 	span := ctx.Value(contextkey.Span).(trace.Span)
 	for k, v := range event.ToFlatMap(e) {
@@ -85,6 +90,15 @@ func (p *custompropagator) Extract(ctx context.Context, hr workflow.HeaderReader
 			return ctx, nil
 		}
 
+		// Drop the Event if it's not valid against its schema, given the enforcement
+		// policy applied.
+		if !event.Enforce(e) {
+			return ctx, nil
+		}
+
+		// Enrich the Event as it enters the service.
+		e = event.Enrich(ctx, e)
+
 		// Retrieve the current span, and set Event's attributes. Make sure a span
 		// is set.
 		span, ok := ctx.Value(contextkey.Span).(trace.Span)
@@ -118,6 +132,15 @@ func (p *custompropagator) ExtractToWorkflow(ctx workflow.Context, hr workflow.H
 			return ctx, nil
 		}
 
+		// Drop the Event if it's not valid against its schema, given the enforcement
+		// policy applied.
+		if !event.Enforce(e) {
+			return ctx, nil
+		}
+
+		// The Event is not enriched in workflows: enrichers are not deterministic,
+		// and workflows are replayed. It has already been enriched by the client
+		// starting the workflow, so the payload is used as-is.
 		// Retrieve the current span, and set Event's attributes. Make sure a span
 		// is set.
 		span, ok := ctx.Value(contextkey.Span).(trace.Span)
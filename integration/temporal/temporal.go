@@ -1,6 +1,9 @@
 package temporal
 
 import (
+	"context"
+	"sync"
+
 	"go.nunchi.studio/helix/errorstack"
 	"go.nunchi.studio/helix/service"
 
@@ -24,6 +27,17 @@ type connection struct {
 
 	// worker holds the Temporal worker, if applicable. It's nil otherwise.
 	worker worker.Worker
+
+	// readyMutex allows to lock/unlock access to the ready channel, since it is
+	// recreated each time the integration is closed.
+	readyMutex sync.Mutex
+
+	// ready is closed once the Temporal worker is started, or right away when
+	// starting the integration if no worker is enabled.
+	ready chan struct{}
+
+	// isReady informs if the ready channel is closed.
+	isReady bool
 }
 
 /*
@@ -33,7 +47,7 @@ if Config is not valid or if the connection failed.
 func Connect(cfg Config) (Client, Worker, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -42,6 +56,7 @@ func Connect(cfg Config) (Client, Worker, error) {
 	stack := errorstack.New("Failed to initialize integration", errorstack.WithIntegration(identifier))
 	conn := &connection{
 		config: &cfg,
+		ready:  make(chan struct{}),
 	}
 
 	// Try to build the tracer.
@@ -69,9 +84,13 @@ func Connect(cfg Config) (Client, Worker, error) {
 
 	// Set TLS options only if enabled in Config.
 	if cfg.TLS.Enabled {
-		var validations []errorstack.Validation
 
-		opts.ConnectionOptions.TLS, validations = cfg.TLS.ToStandardTLS()
+		// Resolve the secrets referenced in Config first, if any.
+		validations := cfg.TLS.ResolveSecrets(context.Background())
+		if len(validations) == 0 {
+			opts.ConnectionOptions.TLS, validations = cfg.TLS.ToStandardTLS(cfg.Address)
+		}
+
 		if len(validations) > 0 {
 			stack.WithValidations(validations...)
 		}
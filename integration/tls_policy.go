@@ -0,0 +1,213 @@
+package integration
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.nunchi.studio/helix/errorstack"
+)
+
+/*
+ClientAuth is the policy of a TLS server for the authentication of clients with
+certificates, a.k.a. mutual TLS.
+*/
+type ClientAuth string
+
+/*
+Policies a TLS server can apply for the authentication of clients.
+*/
+const (
+
+	// ClientAuthNone doesn't request a certificate from clients. This is the
+	// default policy.
+	ClientAuthNone ClientAuth = ""
+
+	// ClientAuthRequest requests a certificate from clients, but doesn't require
+	// nor verify it.
+	ClientAuthRequest ClientAuth = "request"
+
+	// ClientAuthRequire requires a certificate from clients, but doesn't verify it.
+	ClientAuthRequire ClientAuth = "require"
+
+	// ClientAuthVerifyIfGiven requests a certificate from clients, and verifies it
+	// against the client CAs if given.
+	ClientAuthVerifyIfGiven ClientAuth = "verify_if_given"
+
+	// ClientAuthVerify requires a certificate from clients, and verifies it against
+	// the client CAs.
+	ClientAuthVerify ClientAuth = "verify"
+)
+
+/*
+clientAuthTypes maps the client authentication policies to Go standard ones.
+*/
+var clientAuthTypes = map[ClientAuth]tls.ClientAuthType{
+	ClientAuthNone:          tls.NoClientCert,
+	ClientAuthRequest:       tls.RequestClientCert,
+	ClientAuthRequire:       tls.RequireAnyClientCert,
+	ClientAuthVerifyIfGiven: tls.VerifyClientCertIfGiven,
+	ClientAuthVerify:        tls.RequireAndVerifyClientCert,
+}
+
+/*
+versions maps the TLS versions supported to Go standard ones.
+*/
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+/*
+curves maps the names of the elliptic curves supported to Go standard ones.
+*/
+var curves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P256":   tls.CurveP256,
+	"P384":   tls.CurveP384,
+	"P521":   tls.CurveP521,
+}
+
+/*
+sanitizePolicy validates the TLS policy of the configuration.
+*/
+func (cfg *ConfigTLS) sanitizePolicy() []errorstack.Validation {
+	var validations []errorstack.Validation
+
+	if _, ok := clientAuthTypes[cfg.ClientAuth]; !ok {
+		validations = append(validations, errorstack.Validation{
+			Message: fmt.Sprintf("ClientAuth %q is not supported", cfg.ClientAuth),
+			Path:    []string{"Config", "TLS", "ClientAuth"},
+		})
+	}
+
+	if (cfg.ClientAuth == ClientAuthVerifyIfGiven || cfg.ClientAuth == ClientAuthVerify) && len(cfg.ClientCAFiles) == 0 {
+		validations = append(validations, errorstack.Validation{
+			Message: "ClientCAFiles must be set and not be empty for verifying client certificates",
+			Path:    []string{"Config", "TLS", "ClientCAFiles"},
+		})
+	}
+
+	if _, ok := versions[cfg.MinVersion]; !ok && cfg.MinVersion != "" {
+		validations = append(validations, errorstack.Validation{
+			Message: fmt.Sprintf("MinVersion %q is not supported", cfg.MinVersion),
+			Path:    []string{"Config", "TLS", "MinVersion"},
+		})
+	}
+
+	for i, name := range cfg.CipherSuites {
+		if cipherSuite(name) == 0 {
+			validations = append(validations, errorstack.Validation{
+				Message: fmt.Sprintf("Cipher suite %q is not supported or not secure", name),
+				Path:    []string{"Config", "TLS", "CipherSuites", strconv.Itoa(i)},
+			})
+		}
+	}
+
+	for i, name := range cfg.CurvePreferences {
+		if _, ok := curves[name]; !ok {
+			validations = append(validations, errorstack.Validation{
+				Message: fmt.Sprintf("Curve %q is not supported", name),
+				Path:    []string{"Config", "TLS", "CurvePreferences", strconv.Itoa(i)},
+			})
+		}
+	}
+
+	// Pinning SANs of a certificate not verified would give a false assurance,
+	// since anyone can issue a self-signed certificate with the SANs pinned.
+	if len(cfg.AllowedSANs) > 0 {
+		switch cfg.ClientAuth {
+		case ClientAuthRequest, ClientAuthRequire:
+			validations = append(validations, errorstack.Validation{
+				Message: "AllowedSANs can only be set when client certificates are verified, with ClientAuth set to verify_if_given or verify",
+				Path:    []string{"Config", "TLS", "AllowedSANs"},
+			})
+		case ClientAuthNone:
+			if cfg.InsecureSkipVerify {
+				validations = append(validations, errorstack.Validation{
+					Message: "AllowedSANs can not be set when InsecureSkipVerify is true, since the server's certificate is not verified",
+					Path:    []string{"Config", "TLS", "AllowedSANs"},
+				})
+			}
+		}
+	}
+
+	for i, san := range cfg.AllowedSANs {
+		if strings.TrimSuffix(san, "*") == "" {
+			validations = append(validations, errorstack.Validation{
+				Message: "SAN must not be empty nor match any value",
+				Path:    []string{"Config", "TLS", "AllowedSANs", strconv.Itoa(i)},
+			})
+		}
+	}
+
+	return validations
+}
+
+/*
+cipherSuite returns the ID of the secure cipher suite given its name. Returns 0 if
+the cipher suite is not supported or not secure.
+*/
+func cipherSuite(name string) uint16 {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID
+		}
+	}
+
+	return 0
+}
+
+/*
+applyPolicy applies the TLS policy of the configuration to the Go standard *tls.Config
+passed. The configuration must have been sanitized.
+*/
+func (cfg *ConfigTLS) applyPolicy(tlsConfig *tls.Config) {
+	tlsConfig.MinVersion = tls.VersionTLS12
+	if version, ok := versions[cfg.MinVersion]; ok {
+		tlsConfig.MinVersion = version
+	}
+
+	for _, name := range cfg.CipherSuites {
+		if id := cipherSuite(name); id != 0 {
+			tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+		}
+	}
+
+	for _, name := range cfg.CurvePreferences {
+		if curve, ok := curves[name]; ok {
+			tlsConfig.CurvePreferences = append(tlsConfig.CurvePreferences, curve)
+		}
+	}
+}
+
+/*
+verifySAN verifies that the certificate passed has at least one URI or DNS Subject
+Alternative Name allowed by the configuration. An allowed SAN ending with "*"
+matches any SAN starting with the same prefix. Every certificate is allowed if no
+SAN is configured.
+*/
+func (cfg *ConfigTLS) verifySAN(cert *x509.Certificate) error {
+	if len(cfg.AllowedSANs) == 0 {
+		return nil
+	}
+
+	sans := slices.Clone(cert.DNSNames)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	for _, allowed := range cfg.AllowedSANs {
+		for _, san := range sans {
+			prefix, wildcard := strings.CutSuffix(allowed, "*")
+			if san == allowed || (wildcard && strings.HasPrefix(san, prefix)) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("tls: certificate SANs %v are not allowed", sans)
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/errorstack/validate"
 	"go.nunchi.studio/helix/integration"
 )
 
@@ -33,7 +34,10 @@ type Config struct {
 	Namespace string `json:"namespace"`
 
 	// Token sets the token to use, if not already set via environment variable.
-	Token string `json:"-"`
+	// It can also reference a secret resolved when connecting, such as:
+	//
+	//   "file:///run/secrets/vault"
+	Token string `json:"-" config:"token"`
 
 	// TLSConfig configures TLS to communicate with the Vault server.
 	//
@@ -42,10 +46,10 @@ type Config struct {
 }
 
 /*
-sanitize sets default values - when applicable - and validates the configuration.
+Sanitize sets default values - when applicable - and validates the configuration.
 Returns an error if configuration is not valid.
 */
-func (cfg *Config) sanitize() error {
+func (cfg *Config) Sanitize() error {
 	stack := errorstack.New("Failed to validate configuration", errorstack.WithIntegration(identifier))
 
 	if cfg.Address == "" {
@@ -56,13 +60,12 @@ func (cfg *Config) sanitize() error {
 		cfg.Namespace = os.Getenv("VAULT_NAMESPACE")
 	}
 
-	if cfg.Token == "" && os.Getenv("VAULT_TOKEN") == "" {
-		stack.WithValidations(errorstack.Validation{
-			Message: "Token must be set and not be empty",
-			Path:    []string{"Config", "Token"},
-		})
+	v := validate.New("Config")
+	if os.Getenv("VAULT_TOKEN") == "" {
+		v.Field("Token", cfg.Token).Required()
 	}
 
+	stack.WithValidations(v.Validations()...)
 	stack.WithValidations(cfg.TLS.Sanitize()...)
 	if stack.HasValidations() {
 		return stack
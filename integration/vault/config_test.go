@@ -35,6 +35,7 @@ func TestConfig_Sanitize(t *testing.T) {
 				Validations: []errorstack.Validation{
 					{
 						Message: "Token must be set and not be empty",
+						Code:    "required",
 						Path:    []string{"Config", "Token"},
 					},
 				},
@@ -43,7 +44,7 @@ func TestConfig_Sanitize(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		err := tc.before.sanitize()
+		err := tc.before.Sanitize()
 
 		assert.Equal(t, tc.before, tc.after)
 		assert.Equal(t, tc.err, err)
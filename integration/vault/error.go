@@ -0,0 +1,49 @@
+package vault
+
+import (
+	"errors"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/hashicorp/vault/api"
+)
+
+/*
+Classify returns an error wrapping the error returned by the Vault client, with
+the canonical code matching it. The original error can still be matched with
+errors.Is and errors.As. Returns nil if the error passed is nil.
+
+Example:
+
+	secret, err := kv.Get(ctx, "path")
+	if err != nil {
+	  rest.WriteError(rw, req, vault.Classify(err))
+	  return
+	}
+*/
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return errorstack.NewFromError(err, errorstack.WithIntegration(identifier), errorstack.WithCode(classify(err)))
+}
+
+/*
+classify returns the canonical code of the error passed. Errors returned by the
+Vault server are classified given their HTTP status code.
+*/
+func classify(err error) errorstack.Code {
+	if errors.Is(err, api.ErrSecretNotFound) {
+		return errorstack.CodeNotFound
+	}
+
+	var resErr *api.ResponseError
+	if errors.As(err, &resErr) {
+		if code := errorstack.CodeFromHTTPStatus(resErr.StatusCode); code != "" {
+			return code
+		}
+	}
+
+	return errorstack.CodeOf(err)
+}
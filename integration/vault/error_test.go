@@ -0,0 +1,45 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testcases := []struct {
+		input    error
+		expected errorstack.Code
+	}{
+		{
+			input:    fmt.Errorf("%w: at secret/data/db", api.ErrSecretNotFound),
+			expected: errorstack.CodeNotFound,
+		},
+		{
+			input:    &api.ResponseError{StatusCode: 403},
+			expected: errorstack.CodePermissionDenied,
+		},
+		{
+			input:    &api.ResponseError{StatusCode: 503},
+			expected: errorstack.CodeUnavailable,
+		},
+		{
+			input:    errors.New("unexpected"),
+			expected: errorstack.CodeUnknown,
+		},
+	}
+
+	for _, tc := range testcases {
+		err := Classify(tc.input)
+
+		assert.Equal(t, tc.expected, errorstack.CodeOf(err))
+		assert.ErrorIs(t, err, tc.input)
+	}
+
+	assert.Nil(t, Classify(nil))
+}
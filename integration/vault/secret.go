@@ -0,0 +1,74 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+/*
+resolveSecret resolves a secret stored in a Key-Value v2, given a reference made
+of the mount path, the secret path, and the key of the value, such as:
+
+	"secret/data/db#password"
+
+A specific version of the secret can be resolved with:
+
+	"secret/data/db?version=2#password"
+
+It is registered as the secret resolver for the "vault" scheme once connected,
+so other integrations can reference secrets in their Config, such as:
+
+	postgres.Config{
+	  Password: "vault://secret/data/db#password",
+	}
+*/
+func (conn *connection) resolveSecret(ctx context.Context, reference string) (string, error) {
+	path, key, found := strings.Cut(reference, "#")
+	if !found || key == "" {
+		return "", fmt.Errorf("reference must end with the key of the value, such as %q", "#password")
+	}
+
+	path, query, _ := strings.Cut(path, "?")
+	mountpath, secretpath, found := strings.Cut(path, "/")
+	if !found || mountpath == "" || secretpath == "" {
+		return "", fmt.Errorf("reference must start with the mount path and the secret path, such as %q", "secret/data/db")
+	}
+
+	// The Key-Value v2 client already adds "data" to the secret path.
+	secretpath = strings.TrimPrefix(secretpath, "data/")
+
+	var version int
+	if v, found := strings.CutPrefix(query, "version="); found {
+		var err error
+
+		version, err = strconv.Atoi(v)
+		if err != nil || version <= 0 {
+			return "", fmt.Errorf("version must be a positive integer")
+		}
+	}
+
+	var secret *api.KVSecret
+	var err error
+
+	kv := conn.KeyValue(ctx, mountpath)
+	if version > 0 {
+		secret, err = kv.GetVersion(ctx, secretpath, version)
+	} else {
+		secret, err = kv.Get(ctx, secretpath)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secret.Data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("key %q must exist in secret and be a string", key)
+	}
+
+	return value, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
 	"go.nunchi.studio/helix/service"
 
 	"github.com/hashicorp/vault/api"
@@ -37,7 +38,7 @@ if Config is not valid or if the connection failed.
 func Connect(cfg Config) (Vault, error) {
 
 	// No need to continue if Config is not valid.
-	err := cfg.sanitize()
+	err := cfg.Sanitize()
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +49,13 @@ func Connect(cfg Config) (Vault, error) {
 		config: &cfg,
 	}
 
+	// Resolve the token if it references a secret, such as from a file. Stop here
+	// if error validations were encountered.
+	stack.WithValidations(integration.ResolveSecret(context.Background(), &cfg.Token, "Config", "Token")...)
+	if stack.HasValidations() {
+		return nil, stack
+	}
+
 	// Set the default Vault config.
 	var opts = &api.Config{
 		Address:      cfg.Address,
@@ -99,6 +107,9 @@ func Connect(cfg Config) (Vault, error) {
 		return nil, err
 	}
 
+	// Allow other integrations to reference secrets stored in Vault.
+	integration.RegisterSecretResolver(identifier, conn.resolveSecret)
+
 	return conn, nil
 }
 
@@ -0,0 +1,6 @@
+/*
+Package recovery allows to recover from panics at every boundary owned by helix,
+such as goroutines started by the service and handlers wrapped by integrations.
+This is for internal purposes only.
+*/
+package recovery
@@ -0,0 +1,106 @@
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/internal/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+/*
+subscribers holds the channels notified when a panic is recovered.
+*/
+var (
+	subscribers = make(map[chan<- error]struct{})
+	mutex       sync.Mutex
+)
+
+/*
+Subscribe registers a channel notified with the error each time a panic is recovered.
+The channel is not blocked on: if it is full, the notification is dropped. Returns
+the function for unsubscribing.
+*/
+func Subscribe(ch chan<- error) func() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	subscribers[ch] = struct{}{}
+	return func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		delete(subscribers, ch)
+	}
+}
+
+/*
+Recover converts a value recovered from a panic into an error, just like FromPanic.
+Subscribers are then notified, so the service can apply its panic policy. It must
+be called from the deferred function that recovered the panic, so the stack
+captured is the one of the panic.
+
+Example:
+
+	defer func() {
+	  if r := recover(); r != nil {
+	    recovery.Recover(ctx, r, identifier)
+	  }
+	}()
+*/
+func Recover(ctx context.Context, recovered any, integration string) error {
+	err := FromPanic(ctx, recovered, integration)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for ch := range subscribers {
+		select {
+		case ch <- err:
+		default:
+		}
+	}
+
+	return err
+}
+
+/*
+FromPanic converts a value recovered from a panic into an error, along the stack
+of the panicking goroutine. The error is recorded on the span found in the context
+(if any) and logged with the stack. Unlike Recover, subscribers are not notified.
+It must be called from the deferred function that recovered the panic, so the
+stack captured is the one of the panic.
+*/
+func FromPanic(ctx context.Context, recovered any, integration string) error {
+	stack := debug.Stack()
+
+	err := errorstack.New("Recovered from panic", errorstack.WithIntegration(integration))
+	err.WithValidations(errorstack.Validation{
+		Message: fmt.Sprintf("%v", recovered),
+	})
+
+	// Keep the error the goroutine panicked with, if any, so it can still be
+	// matched with errors.Is and errors.As.
+	if cause, ok := recovered.(error); ok {
+		err.Cause = cause
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(
+		attribute.String("exception.stacktrace", string(stack)),
+	))
+	span.SetStatus(codes.Error, "recovered from panic")
+
+	fields := logger.FromContextToZapFields(ctx)
+	fields = append(fields, zap.String("stacktrace", string(stack)))
+	logger.Logger().Error(err.Error(), fields...)
+
+	return err
+}
@@ -0,0 +1,295 @@
+package tracer
+
+import (
+	"path"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/internal/logger"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+)
+
+/*
+Limits of a Baggage enforced by OpenTelemetry, as defined by the W3C Baggage
+specification. A Baggage exceeding them can not be created.
+*/
+const (
+	maxBaggageMembers       = 180
+	maxBytesPerMember       = 4096
+	maxBytesPerBaggageValue = 8192
+)
+
+/*
+BaggagePolicy controls which fields of an Event are propagated across services
+through a Baggage. Field paths are the keys of the flat map of an Event, where
+array indexes are separated by dots, such as "event.subscriptions.0.id". Patterns
+follow the syntax of path.Match, so "event.meta.*" matches all keys of the Meta
+of the Event.
+*/
+type BaggagePolicy struct {
+
+	// Allow is the list of field paths allowed to be propagated. If empty, all
+	// fields are allowed except the denied ones.
+	//
+	// Example:
+	//
+	//   []string{"event.name", "event.user_id", "event.subscriptions.*"}
+	Allow []string
+
+	// Deny is the list of field paths that must not be propagated. It takes
+	// precedence over Allow. No field is denied by default: sensitive fields, such
+	// as the IP address or the location, must be explicitly denied to not leave
+	// the service.
+	//
+	// Example:
+	//
+	//   []string{"event.ip", "event.location.*"}
+	Deny []string
+
+	// Priority is the list of field paths to keep first when the Baggage exceeds
+	// MaxBytes. Fields matching an earlier pattern are kept before fields matching
+	// a later one, and fields not matching any pattern are kept last. Fields with
+	// the same priority are kept in alphabetical order, so truncation is always
+	// deterministic. Defaults to the identifiers of the Event.
+	Priority []string
+
+	// MaxBytes is the maximum size, in bytes, of the Baggage once encoded. It can
+	// not exceed the 8192 bytes allowed by the W3C Baggage specification, which is
+	// the default value.
+	MaxBytes int
+}
+
+/*
+defaultBaggagePriority is the priority applied when none is set in the policy.
+It ensures the Event can be rebuilt in other services even when fields have been
+truncated.
+*/
+var defaultBaggagePriority = []string{
+	"event.name",
+	"event.id",
+	"event.user_id",
+	"event.group_id",
+	"event.tenant_id",
+	"event.is_anonymous",
+	"event.timestamp",
+}
+
+/*
+droppedInterval is the minimum duration between two logs of fields dropped from
+a Baggage. A Baggage is built for every span, so an Event with a field that can
+not be propagated would otherwise log a warning for each one.
+*/
+const droppedInterval = time.Minute
+
+/*
+dropped holds the state of the logs of fields dropped from a Baggage.
+*/
+var dropped struct {
+	mutex sync.Mutex
+
+	// last is the time of the last log.
+	last time.Time
+
+	// skipped is the number of Baggages with dropped fields not logged since the
+	// last log.
+	skipped int
+}
+
+/*
+policy is the BaggagePolicy currently applied.
+*/
+var policy atomic.Pointer[BaggagePolicy]
+
+/*
+SetBaggagePolicy sets the policy applied when propagating an Event through a
+Baggage. Returns an error if a pattern or the maximum size is not valid.
+*/
+func SetBaggagePolicy(p BaggagePolicy) error {
+	stack := errorstack.New("Failed to validate baggage policy")
+	lists := []struct {
+		field    string
+		patterns []string
+	}{
+		{field: "Allow", patterns: p.Allow},
+		{field: "Deny", patterns: p.Deny},
+		{field: "Priority", patterns: p.Priority},
+	}
+
+	for _, list := range lists {
+		for _, pattern := range list.patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				stack.WithValidations(errorstack.Validation{
+					Message: "Pattern must be a valid path pattern",
+					Code:    "pattern",
+					Params:  map[string]any{"pattern": pattern},
+					Path:    []string{"BaggagePolicy", list.field},
+				})
+			}
+		}
+	}
+
+	if p.MaxBytes < 0 || p.MaxBytes > maxBytesPerBaggageValue {
+		stack.WithValidations(errorstack.Validation{
+			Message: "MaxBytes must be between 0 and 8192",
+			Code:    "maximum",
+			Params:  map[string]any{"max": float64(maxBytesPerBaggageValue)},
+			Path:    []string{"BaggagePolicy", "MaxBytes"},
+		})
+	}
+
+	if stack.HasValidations() {
+		return stack
+	}
+
+	if p.MaxBytes == 0 {
+		p.MaxBytes = maxBytesPerBaggageValue
+	}
+
+	if len(p.Priority) == 0 {
+		p.Priority = defaultBaggagePriority
+	}
+
+	policy.Store(&p)
+	return nil
+}
+
+/*
+currentBaggagePolicy returns the policy currently applied, or the default one if
+none has been set.
+*/
+func currentBaggagePolicy() *BaggagePolicy {
+	if p := policy.Load(); p != nil {
+		return p
+	}
+
+	return &BaggagePolicy{
+		Priority: defaultBaggagePriority,
+		MaxBytes: maxBytesPerBaggageValue,
+	}
+}
+
+/*
+matchAny informs if the key passed matches at least one of the patterns.
+*/
+func matchAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+rank returns the priority of the key passed, the lowest being kept first.
+*/
+func (p *BaggagePolicy) rank(key string) int {
+	for i, pattern := range p.Priority {
+		if ok, _ := path.Match(pattern, key); ok {
+			return i
+		}
+	}
+
+	return len(p.Priority)
+}
+
+/*
+allowed informs if the key passed can be propagated given the allow and deny
+lists of the policy.
+*/
+func (p *BaggagePolicy) allowed(key string) bool {
+	if matchAny(p.Deny, key) {
+		return false
+	}
+
+	return len(p.Allow) == 0 || matchAny(p.Allow, key)
+}
+
+/*
+apply builds the Baggage members from the flat map passed, given the policy.
+Values are percent-encoded when the Baggage is encoded, so they can contain any
+character. Fields that can not be propagated because of an invalid key or the
+size limits are logged, so they are never silently dropped.
+*/
+func (p *BaggagePolicy) apply(flatten map[string]string) []baggage.Member {
+	keys := make([]string, 0, len(flatten))
+	for k := range flatten {
+		if p.allowed(k) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		ri, rj := p.rank(keys[i]), p.rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	var members []baggage.Member
+	var invalid, truncated []string
+	var size int
+	for _, k := range keys {
+		// Keys can not be percent-encoded, so members with a key not valid against
+		// the W3C Baggage specification would be silently ignored once encoded.
+		m, err := baggage.NewMemberRaw(k, flatten[k])
+		if err != nil || m.String() == "" {
+			invalid = append(invalid, k)
+			continue
+		}
+
+		// Account for the comma separating members once encoded.
+		n := len(m.String())
+		if len(members) > 0 {
+			n++
+		}
+
+		if n > maxBytesPerMember || size+n > p.MaxBytes || len(members) == maxBaggageMembers {
+			truncated = append(truncated, k)
+			continue
+		}
+
+		size += n
+		members = append(members, m)
+	}
+
+	if len(invalid) > 0 || len(truncated) > 0 {
+		p.logDropped(invalid, truncated)
+	}
+
+	return members
+}
+
+/*
+logDropped logs the fields dropped from a Baggage, at most once per interval. The
+number of Baggages with dropped fields not logged since the last log is added to
+the log, so drops are never silent.
+*/
+func (p *BaggagePolicy) logDropped(invalid []string, truncated []string) {
+	dropped.mutex.Lock()
+	if time.Since(dropped.last) < droppedInterval {
+		dropped.skipped++
+		dropped.mutex.Unlock()
+		return
+	}
+
+	skipped := dropped.skipped
+	dropped.last = time.Now()
+	dropped.skipped = 0
+	dropped.mutex.Unlock()
+
+	logger.Logger().Warn("Event fields have been dropped from baggage",
+		zap.Strings("invalid", invalid),
+		zap.Strings("truncated", truncated),
+		zap.Int("max_bytes", p.MaxBytes),
+		zap.Int("skipped", skipped),
+	)
+}
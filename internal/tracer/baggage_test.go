@@ -0,0 +1,139 @@
+package tracer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/event"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestFromContextToBaggageMembers(t *testing.T) {
+	defer policy.Store(nil)
+
+	ctx := event.ContextWithEvent(context.Background(), event.Event{
+		Name:      "subscribed",
+		UserID:    "user_2N6YZQLcYy2SPtmHiII69yHp0WE",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64)",
+		Meta: map[string]string{
+			"discount":   "100%",
+			"promo code": "value",
+		},
+		Subscriptions: []event.Subscription{
+			{
+				ID:     "sub_2N6YZQXgQAv87zMmvlHxePCSsRs",
+				PlanID: "plan_2N6YZSE1SkWT9DrlXlswLhJ5K5Q",
+			},
+		},
+	})
+
+	testcases := []struct {
+		policy   BaggagePolicy
+		expected map[string]string
+	}{
+		{
+			policy: BaggagePolicy{},
+			expected: map[string]string{
+				"event.name":                         "subscribed",
+				"event.user_id":                      "user_2N6YZQLcYy2SPtmHiII69yHp0WE",
+				"event.user_agent":                   "Mozilla/5.0 (X11; Linux x86_64)",
+				"event.meta.discount":                "100%",
+				"event.meta.promo%20code":            "value",
+				"event.subscriptions.0.id":           "sub_2N6YZQXgQAv87zMmvlHxePCSsRs",
+				"event.subscriptions.0.increment_by": "0",
+				"event.subscriptions.0.plan_id":      "plan_2N6YZSE1SkWT9DrlXlswLhJ5K5Q",
+			},
+		},
+		{
+			policy: BaggagePolicy{
+				Allow: []string{"event.name", "event.user_*", "event.subscriptions.*"},
+				Deny:  []string{"event.user_agent", "event.subscriptions.*.plan_id"},
+			},
+			expected: map[string]string{
+				"event.name":                         "subscribed",
+				"event.user_id":                      "user_2N6YZQLcYy2SPtmHiII69yHp0WE",
+				"event.subscriptions.0.id":           "sub_2N6YZQXgQAv87zMmvlHxePCSsRs",
+				"event.subscriptions.0.increment_by": "0",
+			},
+		},
+		{
+			policy: BaggagePolicy{
+				Priority: []string{"event.name", "event.subscriptions.*"},
+				MaxBytes: 180,
+			},
+			expected: map[string]string{
+				"event.name":                         "subscribed",
+				"event.subscriptions.0.id":           "sub_2N6YZQXgQAv87zMmvlHxePCSsRs",
+				"event.subscriptions.0.increment_by": "0",
+				"event.subscriptions.0.plan_id":      "plan_2N6YZSE1SkWT9DrlXlswLhJ5K5Q",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		require.NoError(t, SetBaggagePolicy(tc.policy))
+
+		members := FromContextToBaggageMembers(ctx)
+		b, err := baggage.New(members...)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(b.String()), currentBaggagePolicy().MaxBytes)
+
+		// Ensure values are percent-encoded, and decoded once parsed.
+		parsed, err := baggage.Parse(b.String())
+		require.NoError(t, err)
+		assert.False(t, strings.Contains(b.String(), " "))
+
+		actual := make(map[string]string)
+		for _, m := range parsed.Members() {
+			actual[m.Key()] = m.Value()
+		}
+
+		assert.Equal(t, tc.expected, actual)
+	}
+}
+
+func TestSetBaggagePolicy(t *testing.T) {
+	defer policy.Store(nil)
+
+	err := SetBaggagePolicy(BaggagePolicy{
+		Allow:    []string{"event.["},
+		MaxBytes: 10000,
+	})
+
+	var stack *errorstack.Error
+	require.ErrorAs(t, err, &stack)
+	assert.Len(t, stack.Validations, 2)
+	assert.Nil(t, policy.Load())
+}
+
+func TestBaggagePolicy_LogDropped(t *testing.T) {
+	reset := func() {
+		dropped.last = time.Time{}
+		dropped.skipped = 0
+	}
+
+	reset()
+	defer reset()
+
+	p := currentBaggagePolicy()
+	flatten := map[string]string{
+		"event.name":      "subscribed",
+		"event.meta.note": strings.Repeat("a", maxBytesPerMember),
+	}
+
+	// Dropped fields are only logged once per interval, and the Baggages not
+	// logged are counted.
+	for i := 0; i < 3; i++ {
+		members := p.apply(flatten)
+		assert.Len(t, members, 1)
+	}
+
+	assert.False(t, dropped.last.IsZero())
+	assert.Equal(t, 2, dropped.skipped)
+}
@@ -12,34 +12,31 @@ import (
 
 /*
 FromContextToBaggageMembers tries to extract an event from the context to add
-each field as a baggage member to the trace.
+each field as a baggage member to the trace. Fields are filtered and truncated
+given the BaggagePolicy currently applied.
 */
 func FromContextToBaggageMembers(ctx context.Context) []baggage.Member {
-	var members []baggage.Member
 
 	// Try to extract the event from the context.
 	ectx, ok := event.EventFromContext(ctx)
 	if !ok {
-		return members
+		return nil
 	}
 
 	// Transform the nasted object to a flatten map of string. It is a required
 	// step to pass them from service to service. This also replaces keys part of
 	// an array (to be compatible with Baggage specificiation), such as transforming
 	// "event.subscriptions[0].id" to "event.subscriptions.0.id".
-	mapped := event.ToFlatMap(ectx)
-	for k, v := range mapped {
+	mapped := make(map[string]string)
+	for k, v := range event.ToFlatMap(ectx) {
 		k = strings.ReplaceAll(k, "[", ".")
 		k = strings.ReplaceAll(k, "].", ".")
 		k = strings.ReplaceAll(k, "]", "")
 
-		m, err := baggage.NewMember(k, v)
-		if err == nil {
-			members = append(members, m)
-		}
+		mapped[k] = v
 	}
 
-	return members
+	return currentBaggagePolicy().apply(mapped)
 }
 
 /*
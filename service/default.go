@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.nunchi.studio/helix/event"
+	"go.nunchi.studio/helix/integration"
+	"go.nunchi.studio/helix/telemetry/log"
+)
+
+/*
+svc is the default service. It is the one used by the package functions, such as
+Attach and Start, and therefore the one integrations are attached to when created.
+*/
+var svc atomic.Pointer[Service]
+
+/*
+init ensures the default service exists before any package function is called.
+It also ensures events that could not be sent to a sink in the background, or
+could not be enriched, are logged.
+*/
+func init() {
+	svc.Store(New())
+
+	event.SetErrorHandler(func(err error) {
+		log.Error(context.Background(), "Failed to process events", err)
+	})
+}
+
+/*
+Default returns the default service, used by the package functions of this package.
+*/
+func Default() *Service {
+	return svc.Load()
+}
+
+/*
+SetDefault replaces the default service by the one passed. Integrations created
+afterwards are attached to this service. This is mostly useful for isolating
+services across tests:
+
+	service.SetDefault(service.New())
+
+It should not be called once the default service has been started.
+*/
+func SetDefault(s *Service) {
+	if s != nil {
+		svc.Store(s)
+	}
+}
+
+/*
+Attach allows to attach a third-party integration to the default service. See
+Service.Attach for more details.
+*/
+func Attach(inte integration.Integration) error {
+	return Default().Attach(inte)
+}
+
+/*
+Start initializes the default service, and starts each integration attached. See
+Service.Start for more details.
+*/
+func Start(ctx context.Context) error {
+	return Default().Start(ctx)
+}
+
+/*
+Run owns the full lifecycle of the default service. See Service.Run for more
+details.
+*/
+func Run(ctx context.Context, opts ...WithOnRun) error {
+	return Default().Run(ctx, opts...)
+}
+
+/*
+Close tries to gracefully close the default service. See Service.Close for more
+details.
+*/
+func Close(ctx context.Context) error {
+	return Default().Close(ctx)
+}
+
+/*
+Status executes a health check of each integration attached to the default service.
+See Service.Status for more details.
+*/
+func Status(ctx context.Context) (int, error) {
+	return Default().Status(ctx)
+}
+
+/*
+Health returns the structured health report of the default service. See
+Service.Health for more details.
+*/
+func Health(ctx context.Context) *HealthReport {
+	return Default().Health(ctx)
+}
+
+/*
+WaitReady blocks until every integration attached to the default service is ready,
+or until the context is done. See Service.WaitReady for more details.
+*/
+func WaitReady(ctx context.Context) error {
+	return Default().WaitReady(ctx)
+}
+
+/*
+Readiness indicates if the default service is ready to handle workloads. See
+Service.Readiness for more details.
+*/
+func Readiness(ctx context.Context) (int, error) {
+	return Default().Readiness(ctx)
+}
+
+/*
+SetCritical sets if an integration is critical for the default service. See
+Service.SetCritical for more details.
+*/
+func SetCritical(name string, critical bool) {
+	Default().SetCritical(name, critical)
+}
+
+/*
+SetHealthTTL sets the duration a health report of the default service is cached
+for. See Service.SetHealthTTL for more details.
+*/
+func SetHealthTTL(ttl time.Duration) {
+	Default().SetHealthTTL(ttl)
+}
+
+/*
+SetSupervision sets how the default service supervises an integration. See
+Service.SetSupervision for more details.
+*/
+func SetSupervision(name string, sup Supervision) {
+	Default().SetSupervision(name, sup)
+}
+
+/*
+OnBeforeStart registers a hook executed by the default service before starting
+integrations. See Service.OnBeforeStart for more details.
+*/
+func OnBeforeStart(hook Hook) {
+	Default().OnBeforeStart(hook)
+}
+
+/*
+OnAfterStart registers a hook executed by the default service once every integration
+is ready. See Service.OnAfterStart for more details.
+*/
+func OnAfterStart(hook Hook) {
+	Default().OnAfterStart(hook)
+}
+
+/*
+OnBeforeClose registers a hook executed by the default service before closing
+integrations. See Service.OnBeforeClose for more details.
+*/
+func OnBeforeClose(hook Hook) {
+	Default().OnBeforeClose(hook)
+}
+
+/*
+OnAfterClose registers a hook executed by the default service once every integration
+is closed. See Service.OnAfterClose for more details.
+*/
+func OnAfterClose(hook Hook) {
+	Default().OnAfterClose(hook)
+}
+
+/*
+SetHookTimeout sets the maximum duration given to each hook of the default service.
+See Service.SetHookTimeout for more details.
+*/
+func SetHookTimeout(timeout time.Duration) {
+	Default().SetHookTimeout(timeout)
+}
+
+/*
+SetPanicPolicy sets the policy applied by the default service when a panic is
+recovered. See Service.SetPanicPolicy for more details.
+*/
+func SetPanicPolicy(policy PanicPolicy) {
+	Default().SetPanicPolicy(policy)
+}
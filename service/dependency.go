@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
+)
+
+/*
+buildLayers builds the dependency graph of the integrations passed, and returns
+their index sorted into layers. Integrations are identified by index rather than
+by value, since an integration may not be comparable. Integrations of a layer
+only depend on integrations of previous layers, so each layer can be started once
+the previous one is started. The order in which integrations have been attached
+is kept within a layer.
+
+Returns validation errors if an integration depends on an integration that is not
+attached, or if a dependency cycle is detected.
+*/
+func buildLayers(integrations []integration.Integration) ([][]int, []errorstack.Validation) {
+	var validations []errorstack.Validation
+
+	// Group integrations by name, since an integration can be attached multiple
+	// times (such as connecting to two different PostgreSQL databases).
+	byName := make(map[string][]int)
+	for i, inte := range integrations {
+		byName[inte.String()] = append(byName[inte.String()], i)
+	}
+
+	// Build the edges of the graph: dependents[i] holds the index of every
+	// integration depending on the integration at index i, dependencies[i] holds
+	// the index of every integration the integration at index i depends on, and
+	// indegrees[i] holds the number of dependencies not started yet.
+	dependents := make([][]int, len(integrations))
+	dependencies := make([][]int, len(integrations))
+	indegrees := make([]int, len(integrations))
+	for i, inte := range integrations {
+		dependent, ok := inte.(integration.Dependent)
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, name := range dependent.DependsOn() {
+			if seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			if _, exists := byName[name]; !exists {
+				validations = append(validations, errorstack.Validation{
+					Message: fmt.Sprintf("Integration %q depends on %q which is not attached", inte.String(), name),
+					Path:    []string{"integration.DependsOn()"},
+				})
+
+				continue
+			}
+
+			for _, j := range byName[name] {
+				dependents[j] = append(dependents[j], i)
+				dependencies[i] = append(dependencies[i], j)
+				indegrees[i]++
+			}
+		}
+	}
+
+	if len(validations) > 0 {
+		return nil, validations
+	}
+
+	// Sort the graph into layers using Kahn's algorithm: each layer is made of
+	// the integrations with no remaining dependencies.
+	var layers [][]int
+	var current []int
+	for i := range integrations {
+		if indegrees[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	var sorted int
+	for len(current) > 0 {
+		var next []int
+		for _, i := range current {
+			for _, j := range dependents[i] {
+				indegrees[j]--
+				if indegrees[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+
+		// Keep the order in which integrations have been attached within the next
+		// layer.
+		sort.Ints(next)
+
+		sorted += len(current)
+		layers = append(layers, current)
+		current = next
+	}
+
+	// If some integrations have not been sorted, it means they are part of at
+	// least one dependency cycle.
+	if sorted < len(integrations) {
+		validations = append(validations, errorstack.Validation{
+			Message: fmt.Sprintf("Dependency cycle detected between integrations: %s", strings.Join(findCycle(integrations, dependencies, indegrees), " > ")),
+			Path:    []string{"integration.DependsOn()"},
+		})
+
+		return nil, validations
+	}
+
+	return layers, nil
+}
+
+/*
+findCycle returns the names of the integrations forming a dependency cycle, given
+the remaining indegrees after sorting the graph. Each integration depends on the
+next one, and the first name is repeated at the end so the cycle is easy to read.
+
+Example:
+
+	[]string{"rest", "nats", "rest"}
+*/
+func findCycle(integrations []integration.Integration, dependencies [][]int, indegrees []int) []string {
+	var start = -1
+	for i := range integrations {
+		if indegrees[i] > 0 {
+			start = i
+			break
+		}
+	}
+
+	if start < 0 {
+		return nil
+	}
+
+	// Walk the graph from an unsorted integration by only following unsorted
+	// dependencies. Every unsorted integration has at least one unsorted dependency,
+	// so an integration is eventually visited twice.
+	visited := make(map[int]int)
+	var path []int
+	for i := start; ; {
+		if pos, ok := visited[i]; ok {
+			path = append(path[pos:], i)
+			break
+		}
+
+		visited[i] = len(path)
+		path = append(path, i)
+		for _, j := range dependencies[i] {
+			if indegrees[j] > 0 {
+				i = j
+				break
+			}
+		}
+	}
+
+	names := make([]string, len(path))
+	for i, index := range path {
+		names[i] = integrations[index].String()
+	}
+
+	return names
+}
+
+/*
+dependedOn returns the names of the integrations at least one other integration
+depends on.
+*/
+func dependedOn(integrations []integration.Integration) map[string]bool {
+	depended := make(map[string]bool)
+	for _, inte := range integrations {
+		if dependent, ok := inte.(integration.Dependent); ok {
+			for _, name := range dependent.DependsOn() {
+				depended[name] = true
+			}
+		}
+	}
+
+	return depended
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+mock is a minimal integration used for testing purposes.
+*/
+type mock struct {
+	name      string
+	dependsOn []string
+	status    int
+	err       error
+	fails     int
+	starts    int
+	closes    int
+}
+
+func (m *mock) String() string                  { return m.name }
+func (m *mock) Close(ctx context.Context) error { m.closes++; return nil }
+func (m *mock) DependsOn() []string             { return m.dependsOn }
+
+func (m *mock) Start(ctx context.Context) error {
+	m.starts++
+	if m.starts <= m.fails {
+		return errors.New("failed to start")
+	}
+
+	return nil
+}
+
+func (m *mock) Status(ctx context.Context) (int, error) {
+	if m.status == 0 {
+		return 200, m.err
+	}
+
+	return m.status, m.err
+}
+
+func TestBuildLayers(t *testing.T) {
+	postgres := &mock{name: "postgres"}
+	nats := &mock{name: "nats"}
+	vault := &mock{name: "vault"}
+	natsWithVault := &mock{name: "nats", dependsOn: []string{"vault"}}
+	rest := &mock{name: "rest", dependsOn: []string{"postgres", "nats"}}
+	temporal := &mock{name: "temporal", dependsOn: []string{"rest", "postgres"}}
+	unknown := &mock{name: "rest", dependsOn: []string{"unknown"}}
+	cycleA := &mock{name: "a", dependsOn: []string{"b"}}
+	cycleB := &mock{name: "b", dependsOn: []string{"c"}}
+	cycleC := &mock{name: "c", dependsOn: []string{"a"}}
+	cycleD := &mock{name: "d", dependsOn: []string{"a"}}
+
+	testcases := []struct {
+		input       []integration.Integration
+		expected    [][]integration.Integration
+		validations []errorstack.Validation
+	}{
+		{
+			input:    nil,
+			expected: nil,
+		},
+		{
+			input: []integration.Integration{postgres, nats},
+			expected: [][]integration.Integration{
+				{postgres, nats},
+			},
+		},
+		{
+			input: []integration.Integration{rest, postgres, nats},
+			expected: [][]integration.Integration{
+				{postgres, nats},
+				{rest},
+			},
+		},
+		{
+			input: []integration.Integration{temporal, rest, natsWithVault, postgres, vault},
+			expected: [][]integration.Integration{
+				{postgres, vault},
+				{natsWithVault},
+				{rest},
+				{temporal},
+			},
+		},
+		{
+			input:    []integration.Integration{postgres, unknown},
+			expected: nil,
+			validations: []errorstack.Validation{
+				{
+					Message: `Integration "rest" depends on "unknown" which is not attached`,
+					Path:    []string{"integration.DependsOn()"},
+				},
+			},
+		},
+		{
+			input:    []integration.Integration{cycleD, cycleA, cycleB, cycleC},
+			expected: nil,
+			validations: []errorstack.Validation{
+				{
+					Message: "Dependency cycle detected between integrations: a > b > c > a",
+					Path:    []string{"integration.DependsOn()"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		layers, validations := buildLayers(tc.input)
+
+		var actual [][]integration.Integration
+		for _, layer := range layers {
+			var integrations []integration.Integration
+			for _, i := range layer {
+				integrations = append(integrations, tc.input[i])
+			}
+
+			actual = append(actual, integrations)
+		}
+
+		assert.Equal(t, tc.expected, actual)
+		assert.Equal(t, tc.validations, validations)
+	}
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.nunchi.studio/helix/integration"
+	"go.nunchi.studio/helix/internal/recovery"
+	"go.nunchi.studio/helix/telemetry/log"
+)
+
+/*
+HealthStatus represents the health state of a service or of an integration.
+*/
+type HealthStatus string
+
+/*
+Health states a service or an integration can be in.
+*/
+const (
+	HealthStatusHealthy   HealthStatus = "healthy"
+	HealthStatusDegraded  HealthStatus = "degraded"
+	HealthStatusUnhealthy HealthStatus = "unhealthy"
+)
+
+/*
+HealthReport is the structured health report of a service, as returned by Health.
+It is designed to be served as-is by HTTP integrations.
+*/
+type HealthReport struct {
+
+	// Status is the overall health state of the service:
+	//   - "healthy" if every integration is healthy;
+	//   - "degraded" if only non-critical integrations are unhealthy;
+	//   - "unhealthy" if at least one critical integration is unhealthy.
+	Status HealthStatus `json:"status"`
+
+	// Code is the HTTP status code representing the overall health state of the
+	// service. It is `200` unless the service is unhealthy, in which case it is the
+	// highest status code returned by critical integrations.
+	Code int `json:"code"`
+
+	// Initialized informs if the service has been initialized.
+	Initialized bool `json:"initialized"`
+
+	// Ready informs if every integration attached to the service is ready.
+	Ready bool `json:"ready"`
+
+	// CheckedAt is the time at which the health checks of the report have been
+	// executed.
+	CheckedAt time.Time `json:"checked_at"`
+
+	// Integrations holds the health report of each integration attached to the
+	// service, in the order they have been attached.
+	Integrations []IntegrationHealth `json:"integrations"`
+}
+
+/*
+IntegrationHealth is the health report of a single integration.
+*/
+type IntegrationHealth struct {
+
+	// Name is the name of the integration, as returned by its String function.
+	Name string `json:"name"`
+
+	// Status is the health state of the integration. It is "unhealthy" if the
+	// health check returned an error or a status code greater than or equal to
+	// `400`, "healthy" otherwise.
+	Status HealthStatus `json:"status"`
+
+	// Code is the HTTP status code returned by the health check.
+	Code int `json:"code"`
+
+	// Critical informs if the service can not work properly when the integration
+	// is unhealthy. See SetCritical for more details.
+	Critical bool `json:"critical"`
+
+	// Ready informs if the integration is ready.
+	Ready bool `json:"ready"`
+
+	// Restarts is the number of times the integration has been restarted by the
+	// service after failing to start. See SetSupervision for more details.
+	Restarts int `json:"restarts"`
+
+	// Ignored informs if the integration failed to start and has been ignored by
+	// the service. An ignored integration is never considered critical.
+	Ignored bool `json:"ignored,omitempty"`
+
+	// Latency is the duration of the health check. It is encoded in nanoseconds
+	// in JSON.
+	Latency time.Duration `json:"latency_ns"`
+
+	// CheckedAt is the time at which the health check has been executed.
+	CheckedAt time.Time `json:"checked_at"`
+
+	// LastError is the message of the last error returned by the health check, if
+	// any. It is kept even if the integration is healthy again, so intermittent
+	// failures can be investigated. Since it may contain internal details such as
+	// hosts, it should not be served publicly: see Redacted.
+	LastError string `json:"last_error,omitempty"`
+
+	// LastErrorAt is the time at which the last error has been returned by the
+	// health check, if any.
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+/*
+Redacted returns a copy of the report without the messages of the errors returned
+by health checks, which may contain internal details such as integration hosts or
+driver messages. This is designed for serving the report publicly: messages are
+logged by the service when a health check fails.
+*/
+func (r *HealthReport) Redacted() *HealthReport {
+	redacted := *r
+	redacted.Integrations = make([]IntegrationHealth, len(r.Integrations))
+	for i, inte := range r.Integrations {
+		inte.LastError = ""
+		redacted.Integrations[i] = inte
+	}
+
+	return &redacted
+}
+
+/*
+Liveness returns the HTTP status code to use for a liveness probe. It is always
+`200` since the service is able to build the report, and doesn't depend on the
+integrations' health: an orchestrator must not restart the service because one of
+its dependencies is unavailable.
+*/
+func (r *HealthReport) Liveness() int {
+	return http.StatusOK
+}
+
+/*
+Readiness returns the HTTP status code to use for a readiness probe. It is `200`
+once the service has been initialized, every integration is ready, and no critical
+integration is unhealthy. It is `503` otherwise.
+*/
+func (r *HealthReport) Readiness() int {
+	if !r.Initialized || !r.Ready || r.Status == HealthStatusUnhealthy {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
+
+/*
+Startup returns the HTTP status code to use for a startup probe. It is `200` once
+the service has been initialized and every integration is ready, `503` otherwise.
+Unlike Readiness, it doesn't depend on the integrations' health.
+*/
+func (r *HealthReport) Startup() int {
+	if !r.Initialized || !r.Ready {
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
+
+/*
+defaultHealthTTL is the default duration a health report is cached for.
+*/
+const defaultHealthTTL = 5 * time.Second
+
+/*
+health holds the state of health checks of the service.
+*/
+type health struct {
+
+	// mutex ensures a single report is built at a time, so concurrent probes share
+	// the same health checks.
+	mutex sync.Mutex
+
+	// ttl is the duration a report is cached for. Set to the default one if 0.
+	ttl time.Duration
+
+	// cached holds the results of the latest health checks executed by Health, at
+	// the same index as the integrations attached.
+	cached []IntegrationHealth
+
+	// cachedAt is the time at which the latest health checks have been executed
+	// by Health.
+	cachedAt time.Time
+
+	// critical holds the criticality of integrations, by name. An integration not
+	// in the map is critical.
+	critical map[string]bool
+
+	// checksMutex allows to lock/unlock access to checks, since it is updated both
+	// by Health and Status.
+	checksMutex sync.Mutex
+
+	// checks holds the latest health check of each integration attached, by index.
+	checks map[int]IntegrationHealth
+}
+
+/*
+reset clears the results of health checks, such as when the service is restarted.
+*/
+func (h *health) reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.checksMutex.Lock()
+	defer h.checksMutex.Unlock()
+
+	h.cached = nil
+	h.cachedAt = time.Time{}
+	h.checks = nil
+}
+
+/*
+SetCritical sets if an integration is critical for the service, given the name
+of the integration as returned by its String function. By default, all integrations
+are critical: the service is unhealthy — and not ready — as soon as one of them
+is unhealthy. A non-critical integration being unhealthy only marks the service as
+degraded.
+
+Example:
+
+	service.SetCritical("openfeature", false)
+*/
+func (s *Service) SetCritical(name string, critical bool) {
+	s.health.mutex.Lock()
+	defer s.health.mutex.Unlock()
+
+	if s.health.critical == nil {
+		s.health.critical = make(map[string]bool)
+	}
+
+	s.health.critical[name] = critical
+}
+
+/*
+SetHealthTTL sets the duration a health report is cached for by Health, so probes
+don't execute the health checks of every integration on each request. A negative
+duration disables the cache.
+
+Default:
+
+	5 * time.Second
+*/
+func (s *Service) SetHealthTTL(ttl time.Duration) {
+	s.health.mutex.Lock()
+	defer s.health.mutex.Unlock()
+
+	s.health.ttl = ttl
+}
+
+/*
+Health returns the structured health report of the service. It executes a health
+check of each integration attached, unless health checks have been executed within
+the health TTL, in which case the cached results are used. The initialization and
+readiness states are always up-to-date.
+
+Unlike Status, the report details the state of each integration, and distinguishes
+critical integrations from degraded ones.
+*/
+func (s *Service) Health(ctx context.Context) *HealthReport {
+	s.mutex.Lock()
+	isInitialized := s.isInitialized
+	s.mutex.Unlock()
+
+	integrations, ready := s.readiness()
+
+	s.health.mutex.Lock()
+	defer s.health.mutex.Unlock()
+
+	ttl := s.health.ttl
+	if ttl == 0 {
+		ttl = defaultHealthTTL
+	}
+
+	if len(s.health.cached) != len(integrations) || time.Since(s.health.cachedAt) >= ttl {
+		s.health.cachedAt = time.Now()
+		s.health.cached = s.checkAll(ctx, integrations)
+	}
+
+	report := &HealthReport{
+		Status:       HealthStatusHealthy,
+		Code:         http.StatusOK,
+		Initialized:  isInitialized,
+		Ready:        true,
+		CheckedAt:    s.health.cachedAt,
+		Integrations: make([]IntegrationHealth, len(integrations)),
+	}
+
+	copy(report.Integrations, s.health.cached)
+	for i := range report.Integrations {
+		select {
+		case <-ready[i]:
+			report.Integrations[i].Ready = true
+		default:
+			report.Ready = false
+		}
+
+		critical, exists := s.health.critical[integrations[i].String()]
+		report.Integrations[i].Critical = (critical || !exists) && !report.Integrations[i].Ignored
+
+		if report.Integrations[i].Status != HealthStatusUnhealthy {
+			continue
+		}
+
+		if !report.Integrations[i].Critical {
+			if report.Status == HealthStatusHealthy {
+				report.Status = HealthStatusDegraded
+			}
+
+			continue
+		}
+
+		// An integration can be unhealthy because of an error, even if the status
+		// code returned is not an error one.
+		code := report.Integrations[i].Code
+		if code < http.StatusBadRequest {
+			code = http.StatusServiceUnavailable
+		}
+
+		if report.Status != HealthStatusUnhealthy || code > report.Code {
+			report.Code = code
+		}
+
+		report.Status = HealthStatusUnhealthy
+	}
+
+	return report
+}
+
+/*
+checkAll executes the health check of each integration passed concurrently, and
+returns the health reports at the same index.
+*/
+func (s *Service) checkAll(ctx context.Context, integrations []integration.Integration) []IntegrationHealth {
+	checks := make([]IntegrationHealth, len(integrations))
+
+	var wg sync.WaitGroup
+	for i, inte := range integrations {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			checks[i], _ = s.check(ctx, i, inte)
+		}()
+	}
+
+	wg.Wait()
+	return checks
+}
+
+/*
+check executes the health check of an integration given its index, records the
+result, and returns it along the error returned by the integration, if any.
+*/
+func (s *Service) check(ctx context.Context, index int, inte integration.Integration) (IntegrationHealth, error) {
+	started := time.Now()
+	code, err := status(ctx, inte)
+
+	// An integration not running because it failed to start is not healthy, no
+	// matter the status returned.
+	restarts, ignored, failure := s.supervised(index, inte)
+	if failure != nil {
+		err = failure
+		if code < http.StatusServiceUnavailable {
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	s.health.checksMutex.Lock()
+	defer s.health.checksMutex.Unlock()
+
+	if s.health.checks == nil {
+		s.health.checks = make(map[int]IntegrationHealth)
+	}
+
+	previous := s.health.checks[index]
+	result := previous
+	result.Name = inte.String()
+	result.Status = HealthStatusHealthy
+	result.Code = code
+	result.Latency = time.Since(started)
+	result.CheckedAt = started
+	result.Restarts = restarts
+	result.Ignored = ignored
+
+	if err != nil || code >= http.StatusBadRequest {
+		result.Status = HealthStatusUnhealthy
+	}
+
+	// Only log an error when the integration becomes unhealthy or when the error
+	// changes, so probes don't flood the logs while an integration is unhealthy.
+	if err != nil {
+		if previous.Status != HealthStatusUnhealthy || previous.LastError != err.Error() {
+			log.Warn(ctx, fmt.Sprintf("Health check of integration %q failed", result.Name), err)
+		}
+
+		result.LastError = err.Error()
+		result.LastErrorAt = &started
+	}
+
+	s.health.checks[index] = result
+	return result, err
+}
+
+/*
+status executes the Status function of an integration, and converts a panic into
+an error with a `500` status code.
+*/
+func status(ctx context.Context, inte integration.Integration) (code int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			code, err = http.StatusInternalServerError, recovery.FromPanic(ctx, r, inte.String())
+		}
+	}()
+
+	return inte.Status(ctx)
+}
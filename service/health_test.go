@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.nunchi.studio/helix/integration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	testcases := []struct {
+		integrations []integration.Integration
+		critical     map[string]bool
+		status       HealthStatus
+		code         int
+		readiness    int
+	}{
+		{
+			integrations: []integration.Integration{
+				&mock{name: "postgres"},
+				&mock{name: "nats"},
+			},
+			status:    HealthStatusHealthy,
+			code:      200,
+			readiness: 200,
+		},
+		{
+			integrations: []integration.Integration{
+				&mock{name: "postgres"},
+				&mock{name: "openfeature", err: errors.New("unavailable")},
+			},
+			critical: map[string]bool{
+				"openfeature": false,
+			},
+			status:    HealthStatusDegraded,
+			code:      200,
+			readiness: 200,
+		},
+		{
+			integrations: []integration.Integration{
+				&mock{name: "postgres", status: 503},
+				&mock{name: "nats", err: errors.New("unavailable")},
+			},
+			status:    HealthStatusUnhealthy,
+			code:      503,
+			readiness: 503,
+		},
+	}
+
+	for _, tc := range testcases {
+		s := New()
+		for i, inte := range tc.integrations {
+			assert.NoError(t, s.Attach(inte))
+			close(s.ready[i])
+		}
+
+		for name, critical := range tc.critical {
+			s.SetCritical(name, critical)
+		}
+
+		s.isInitialized = true
+		report := s.Health(context.Background())
+
+		assert.Equal(t, tc.status, report.Status)
+		assert.Equal(t, tc.code, report.Code)
+		assert.Equal(t, tc.readiness, report.Readiness())
+		assert.Equal(t, 200, report.Startup())
+		assert.Len(t, report.Integrations, len(tc.integrations))
+	}
+}
+
+func TestHealth_Cache(t *testing.T) {
+	s := New()
+
+	inte := &mock{name: "postgres"}
+	assert.NoError(t, s.Attach(inte))
+
+	report := s.Health(context.Background())
+	assert.Equal(t, HealthStatusHealthy, report.Status)
+	assert.False(t, report.Ready)
+	assert.Equal(t, 503, report.Startup())
+
+	// The health check is cached, but the readiness state is always up-to-date.
+	inte.err = errors.New("unavailable")
+	close(s.ready[0])
+	s.isInitialized = true
+
+	report = s.Health(context.Background())
+	assert.Equal(t, HealthStatusHealthy, report.Status)
+	assert.True(t, report.Ready)
+	assert.Equal(t, 200, report.Startup())
+
+	s.SetHealthTTL(-1)
+	report = s.Health(context.Background())
+	assert.Equal(t, HealthStatusUnhealthy, report.Status)
+	assert.Equal(t, "unavailable", report.Integrations[0].LastError)
+	assert.NotNil(t, report.Integrations[0].LastErrorAt)
+
+	redacted := report.Redacted()
+	assert.Empty(t, redacted.Integrations[0].LastError)
+	assert.NotNil(t, redacted.Integrations[0].LastErrorAt)
+	assert.Equal(t, "unavailable", report.Integrations[0].LastError)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/internal/recovery"
+)
+
+/*
+Hook is a function executed by the service at a given step of its lifecycle. The
+context passed has a deadline, set by the hook timeout. Returning an error from a
+hook executed when starting the service aborts the startup.
+*/
+type Hook func(ctx context.Context) error
+
+/*
+defaultHookTimeout is the default maximum duration given to each hook.
+*/
+const defaultHookTimeout = 30 * time.Second
+
+/*
+hooks holds the hooks registered for a service.
+*/
+type hooks struct {
+
+	// mutex allows to lock/unlock access to the hooks.
+	mutex sync.Mutex
+
+	// timeout is the maximum duration given to each hook. Set to the default one
+	// if 0.
+	timeout time.Duration
+
+	// beforeStart holds the hooks executed before starting integrations.
+	beforeStart []Hook
+
+	// afterStart holds the hooks executed once every integration is ready.
+	afterStart []Hook
+
+	// beforeClose holds the hooks executed before closing integrations.
+	beforeClose []Hook
+
+	// afterClose holds the hooks executed once every integration is closed.
+	afterClose []Hook
+}
+
+/*
+OnBeforeStart registers a hook executed once the service is initialized, and
+before starting the integrations attached. Integrations are connected but not
+started yet, which makes it the right place for running database migrations. If
+the hook returns an error, integrations are not started and the startup is aborted.
+*/
+func (s *Service) OnBeforeStart(hook Hook) {
+	s.hooks.mutex.Lock()
+	defer s.hooks.mutex.Unlock()
+
+	s.hooks.beforeStart = append(s.hooks.beforeStart, hook)
+}
+
+/*
+OnAfterStart registers a hook executed once every integration attached is ready,
+such as for warming up caches. If the hook returns an error, the startup is aborted
+and the service stops.
+*/
+func (s *Service) OnAfterStart(hook Hook) {
+	s.hooks.mutex.Lock()
+	defer s.hooks.mutex.Unlock()
+
+	s.hooks.afterStart = append(s.hooks.afterStart, hook)
+}
+
+/*
+OnBeforeClose registers a hook executed before closing the integrations attached,
+such as for flushing buffers or deregistering the service. If the hook returns an
+error, the error is returned when closing the service but integrations are still
+closed.
+*/
+func (s *Service) OnBeforeClose(hook Hook) {
+	s.hooks.mutex.Lock()
+	defer s.hooks.mutex.Unlock()
+
+	s.hooks.beforeClose = append(s.hooks.beforeClose, hook)
+}
+
+/*
+OnAfterClose registers a hook executed once every integration attached is closed,
+and before draining/closing the tracer and logger. If the hook returns an error,
+the error is returned when closing the service.
+*/
+func (s *Service) OnAfterClose(hook Hook) {
+	s.hooks.mutex.Lock()
+	defer s.hooks.mutex.Unlock()
+
+	s.hooks.afterClose = append(s.hooks.afterClose, hook)
+}
+
+/*
+SetHookTimeout sets the maximum duration given to each hook. The context passed
+to a hook is done once the timeout is reached.
+
+Default:
+
+	30 * time.Second
+*/
+func (s *Service) SetHookTimeout(timeout time.Duration) {
+	s.hooks.mutex.Lock()
+	defer s.hooks.mutex.Unlock()
+
+	s.hooks.timeout = timeout
+}
+
+/*
+runHooks executes the hooks returned by the selector passed, in the order they
+have been registered. It stops and returns an error as soon as a hook fails.
+*/
+func (s *Service) runHooks(ctx context.Context, step string, selector func(h *hooks) []Hook) error {
+	s.hooks.mutex.Lock()
+	registered := selector(&s.hooks)
+	timeout := s.hooks.timeout
+	s.hooks.mutex.Unlock()
+
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+
+	for _, hook := range registered {
+		if err := runHook(ctx, step, hook, timeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+runHook executes a hook with the timeout passed. It stops waiting for the hook
+once the timeout is reached, or once the parent context is done, even if the hook
+doesn't respect the context passed. A panic is converted into an error.
+*/
+func runHook(ctx context.Context, step string, hook Hook, timeout time.Duration) error {
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- recovery.FromPanic(hookCtx, r, "")
+			}
+		}()
+
+		done <- hook(hookCtx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-hookCtx.Done():
+	}
+
+	stack := errorstack.New(fmt.Sprintf("Failed to execute hook %s", step))
+
+	// The context of the hook is also done when the parent context is, such as
+	// when the service is closed while starting. The deadline exceeded may then
+	// be the one of the parent context, not the timeout of the hook.
+	if ctx.Err() == nil && errors.Is(hookCtx.Err(), context.DeadlineExceeded) {
+		stack.WithValidations(errorstack.Validation{
+			Message: fmt.Sprintf("Hook has not completed within %s", timeout),
+		})
+
+		return stack
+	}
+
+	stack.WithValidations(errorstack.Validation{
+		Message: "Hook has been interrupted before completing",
+	})
+
+	return stack.WithChildren(ctx.Err())
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	s := New()
+
+	inte := &mock{name: "postgres"}
+	assert.NoError(t, s.Attach(inte))
+
+	var steps []string
+	afterStart := make(chan struct{})
+	s.OnBeforeStart(func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+
+		steps = append(steps, "before start")
+		return nil
+	})
+
+	s.OnAfterStart(func(ctx context.Context) error {
+		steps = append(steps, "after start")
+		close(afterStart)
+		return nil
+	})
+
+	s.OnBeforeClose(func(ctx context.Context) error {
+		steps = append(steps, "before close")
+		return nil
+	})
+
+	s.OnAfterClose(func(ctx context.Context) error {
+		steps = append(steps, "after close")
+		return errors.New("failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan error, 1)
+	go func() {
+		started <- s.Start(ctx)
+	}()
+
+	<-afterStart
+	cancel()
+	assert.NoError(t, <-started)
+
+	assert.Error(t, s.Close(context.Background()))
+	assert.Equal(t, []string{"before start", "after start", "before close", "after close"}, steps)
+	assert.Equal(t, 1, inte.starts)
+	assert.Equal(t, 1, inte.closes)
+}
+
+func TestHooks_Abort(t *testing.T) {
+	timeout := errorstack.New("Failed to execute hook before start")
+	timeout.WithValidations(errorstack.Validation{
+		Message: "Hook has not completed within 10ms",
+	})
+
+	testcases := []struct {
+		hook     Hook
+		expected error
+	}{
+		{
+			hook: func(ctx context.Context) error {
+				return errorstack.New("Failed to run migrations")
+			},
+			expected: errorstack.New("Failed to run migrations"),
+		},
+		{
+			hook: func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+			expected: timeout,
+		},
+	}
+
+	for _, tc := range testcases {
+		s := New()
+		s.SetHookTimeout(10 * time.Millisecond)
+		s.OnBeforeStart(tc.hook)
+
+		inte := &mock{name: "postgres"}
+		assert.NoError(t, s.Attach(inte))
+
+		expected := errorstack.New("Failed to initialize the service")
+		expected.WithChildren(tc.expected)
+
+		assert.Equal(t, expected, s.Start(context.Background()))
+		assert.Equal(t, 0, inte.starts)
+	}
+}
+
+func TestHooks_Interrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	err := runHook(ctx, "before start", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, time.Minute)
+
+	expected := errorstack.New("Failed to execute hook before start")
+	expected.WithValidations(errorstack.Validation{
+		Message: "Hook has been interrupted before completing",
+	})
+
+	assert.Equal(t, expected.WithChildren(context.Canceled), err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
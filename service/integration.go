@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"sync"
 
 	"go.nunchi.studio/helix/errorstack"
@@ -14,12 +16,12 @@ the Init and Close methods of the integration are automatically called when the
 service is initializing and stopping, so they shouldn't be called manually by
 the clients.
 */
-func Attach(inte integration.Integration) error {
-	svc.mutex.Lock()
-	defer svc.mutex.Unlock()
+func (s *Service) Attach(inte integration.Integration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
 	stack := errorstack.New("Failed to attach integration")
-	if svc.isInitialized {
+	if s.isInitialized {
 		stack.WithValidations(errorstack.Validation{
 			Message: "Service must not be initialized for attaching an integration",
 		})
@@ -27,63 +29,88 @@ func Attach(inte integration.Integration) error {
 		return stack
 	}
 
-	if svc.isClosed {
+	if inte == nil {
 		stack.WithValidations(errorstack.Validation{
-			Message: "Service must not be closed for attaching an integration",
+			Message: "Integration must not be nil",
 		})
 
 		return stack
 	}
 
-	if inte == nil {
+	if inte.String() == "" {
 		stack.WithValidations(errorstack.Validation{
-			Message: "Integration must not be nil",
+			Message: "Integration's name must be set and not be empty",
+			Path:    []string{"integration.String()"},
 		})
 
 		return stack
 	}
 
-	if inte.String() == "" {
+	if isAttached(s.integrations, inte) {
 		stack.WithValidations(errorstack.Validation{
-			Message: "Integration's name must be set and not be empty",
-			Path:    []string{"integration.String()"},
+			Message: "Integration must not be attached more than once",
 		})
 
 		return stack
 	}
 
-	svc.integrations = append(svc.integrations, inte)
+	s.integrations = append(s.integrations, inte)
+	s.ready = append(s.ready, make(chan struct{}))
 	return nil
 }
 
+/*
+isAttached informs if the integration passed is part of the integrations attached.
+Integrations that are not comparable, such as structs holding a slice or a map,
+can not be compared and are therefore never considered as attached.
+*/
+func isAttached(integrations []integration.Integration, inte integration.Integration) bool {
+	if !reflect.TypeOf(inte).Comparable() {
+		return false
+	}
+
+	for _, attached := range integrations {
+		if reflect.TypeOf(attached) == reflect.TypeOf(inte) && attached == inte {
+			return true
+		}
+	}
+
+	return false
+}
+
 /*
 Status executes a health check of each integration attached to the service, and
 returns the highest HTTP status code returned. This means if all integrations are
 healthy (status `200`) but one is temporarily unavailable (status `503`), the
-status returned would be `503`.
+status returned would be `503`. An integration not running because it failed to start
+— and is either restarting or ignored given its supervision policy — is considered
+temporarily unavailable.
+
+Use Health for a structured report detailing the state of each integration.
 */
-func Status(ctx context.Context) (int, error) {
+func (s *Service) Status(ctx context.Context) (int, error) {
 
 	// Create a channel that will receive the HTTP status code of the health check
 	// of each integration.
-	chStatus := make(chan int, len(svc.integrations))
-	chError := make(chan error, len(svc.integrations))
+	integrations, _ := s.readiness()
+	chStatus := make(chan int, len(integrations))
+	chError := make(chan error, len(integrations))
 
 	// Go through each integration attached to the service, and execute the health
 	// checks asynchronously. Write the status returned to the channel.
 	var wg sync.WaitGroup
-	for _, inte := range svc.integrations {
+	for i, inte := range integrations {
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
 
-			status, err := inte.Status(ctx)
+			status, err := s.check(ctx, i, inte)
 			if err != nil {
 				chError <- err
 			}
 
-			chStatus <- status
+			chStatus <- status.Code
 		}()
 	}
 
@@ -112,3 +139,91 @@ func Status(ctx context.Context) (int, error) {
 
 	return max, nil
 }
+
+/*
+WaitReady blocks until every integration attached to the service is ready, or
+until the context is done. An integration is ready once its Start function has
+returned with no error, or — for blocking integrations such as servers and workers
+— once the integration informs the service it is ready. Returns an error listing
+the integrations not ready if the context is done first.
+*/
+func (s *Service) WaitReady(ctx context.Context) error {
+	integrations, ready := s.readiness()
+	for i := range integrations {
+		select {
+		case <-ready[i]:
+		case <-ctx.Done():
+			stack := errorstack.New("Service is not ready")
+			stack.WithValidations(notReady(integrations, ready)...)
+
+			return stack
+		}
+	}
+
+	return nil
+}
+
+/*
+Readiness indicates if the service is ready to handle workloads. Unlike Status,
+it doesn't execute health checks: it returns `200` once the service has been
+initialized and every integration attached is ready, `503` otherwise. This is
+designed for readiness probes of orchestrators, while Status is designed for
+health checks.
+*/
+func (s *Service) Readiness(ctx context.Context) (int, error) {
+	stack := errorstack.New("Service is not ready")
+
+	s.mutex.Lock()
+	isInitialized := s.isInitialized
+	s.mutex.Unlock()
+
+	if !isInitialized {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Service must first be initialized",
+		})
+
+		return 503, stack
+	}
+
+	stack.WithValidations(notReady(s.readiness())...)
+	if stack.HasValidations() {
+		return 503, stack
+	}
+
+	return 200, nil
+}
+
+/*
+readiness returns a snapshot of the integrations attached to the service along
+their ready channel, at the same index.
+*/
+func (s *Service) readiness() ([]integration.Integration, []chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	integrations := make([]integration.Integration, len(s.integrations))
+	ready := make([]chan struct{}, len(s.integrations))
+	copy(integrations, s.integrations)
+	copy(ready, s.ready)
+
+	return integrations, ready
+}
+
+/*
+notReady returns a validation failure for each integration whose ready channel is
+not closed yet.
+*/
+func notReady(integrations []integration.Integration, ready []chan struct{}) []errorstack.Validation {
+	var validations []errorstack.Validation
+	for i, inte := range integrations {
+		select {
+		case <-ready[i]:
+		default:
+			validations = append(validations, errorstack.Validation{
+				Message: fmt.Sprintf("Integration %q is not ready", inte.String()),
+			})
+		}
+	}
+
+	return validations
+}
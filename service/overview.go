@@ -1,5 +1,8 @@
 /*
 Package service allows to manage a service leveraging helix.go, as well as managing
 integrations' lifecycle attached to the service.
+
+The package functions, such as Attach, Start, and Close, rely on a default service.
+Isolated services can be created with New, such as for testing purposes.
 */
 package service
@@ -0,0 +1,38 @@
+package service
+
+/*
+PanicPolicy is the policy applied by the service when a panic is recovered at a
+boundary owned by helix, such as within an HTTP handler or a message consumer.
+Panics within the Start function of an integration are handled by the supervision
+policy of the integration instead.
+*/
+type PanicPolicy string
+
+/*
+Policies that can be applied when a panic is recovered.
+*/
+const (
+
+	// PanicPolicyContinue keeps the service running once the panic is recovered,
+	// recorded, and logged. This is the default policy.
+	PanicPolicyContinue PanicPolicy = "continue"
+
+	// PanicPolicyStop stops the service once the panic is recovered, recorded,
+	// and logged, just like when an integration fails to start.
+	PanicPolicyStop PanicPolicy = "stop"
+)
+
+/*
+SetPanicPolicy sets the policy applied by the service when a panic is recovered.
+It must be set before starting the service.
+
+Default:
+
+	PanicPolicyContinue
+*/
+func (s *Service) SetPanicPolicy(policy PanicPolicy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.panicPolicy = policy
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/internal/recovery"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicPolicy(t *testing.T) {
+	testcases := []struct {
+		policy  PanicPolicy
+		stopped bool
+	}{
+		{
+			policy:  PanicPolicyContinue,
+			stopped: false,
+		},
+		{
+			policy:  PanicPolicyStop,
+			stopped: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		s := New()
+		s.SetPanicPolicy(tc.policy)
+		assert.NoError(t, s.Attach(&mock{name: "rest"}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		started := make(chan error, 1)
+		go func() {
+			started <- s.Start(ctx)
+		}()
+
+		assert.NoError(t, s.WaitReady(ctx))
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					recovery.Recover(ctx, r, "rest")
+				}
+			}()
+
+			panic("handler panicked")
+		}()
+
+		select {
+		case err := <-started:
+			assert.True(t, tc.stopped)
+			assert.ErrorContains(t, err, "Recovered from panic")
+		case <-time.After(50 * time.Millisecond):
+			assert.False(t, tc.stopped)
+		}
+
+		cancel()
+		if !tc.stopped {
+			assert.NoError(t, <-started)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/telemetry/log"
+	"go.nunchi.studio/helix/telemetry/trace"
+)
+
+/*
+exit is the function called to force the process to exit when a second interrupting
+signal is catched while shutting down. It can be overridden for testing purposes.
+*/
+var exit = os.Exit
+
+/*
+Run owns the full lifecycle of the service. It initializes the service and starts
+each integration attached, just like Start. Once an interrupting signal is catched,
+the context is done, or an integration returns an error while starting it, it
+gracefully shuts down the service:
+
+ 1. It waits for the pre-stop delay, if any;
+ 2. It closes each integration, in the reverse order they have been started, and
+    within the close timeout of each integration, if any;
+ 3. It drains/closes the tracer and logger.
+
+The whole shutdown must complete within the drain timeout. If a second interrupting
+signal is catched while shutting down, the process exits right away with status
+code 1.
+
+Example:
+
+	err := service.Run(ctx,
+	  service.WithPreStopDelayOnRun(5*time.Second),
+	  service.WithDrainTimeoutOnRun(30*time.Second),
+	)
+*/
+func (s *Service) Run(ctx context.Context, opts ...WithOnRun) error {
+	options := defaultRunOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	layers, err := s.initialize()
+	if err != nil {
+		return err
+	}
+
+	stack := errorstack.New("Failed to run the service")
+
+	// Create a channel for receiving interrupting signals. It is listened to until
+	// the shutdown is complete: the first signal is forwarded to the done channel
+	// to stop serving, and a second signal forces the process to exit. The first
+	// signal is forwarded even if the service is already shutting down, such as
+	// when an integration returned an error, so it doesn't force the exit.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, options.signals...)
+	defer signal.Stop(signals)
+
+	done := make(chan os.Signal, 1)
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		interrupted := false
+		for {
+			select {
+			case sig := <-signals:
+				if !interrupted {
+					interrupted = true
+					done <- sig
+					continue
+				}
+
+				log.Error(ctx, fmt.Sprintf("Catched signal %q while shutting down, forcing exit", sig))
+				exit(1)
+			case <-finished:
+				return
+			}
+		}
+	}()
+
+	// Inform once every integration is ready, as long as the service is serving.
+	serving, stopServing := context.WithCancel(ctx)
+	go func() {
+		if err := s.WaitReady(serving); err == nil {
+			log.Info(ctx, "Service is ready")
+		}
+	}()
+
+	log.Info(ctx, "Service is starting")
+	err = s.serve(ctx, layers, done)
+	stopServing()
+	if err != nil {
+		log.Error(ctx, "Service failed, shutting down")
+		stack.WithChildren(err)
+	}
+
+	// The shutdown must complete within the drain timeout, even if the context
+	// passed is already done.
+	drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), options.drainTimeout)
+	defer cancel()
+
+	drainCtx, span := trace.Start(drainCtx, trace.SpanKindInternal, "Service: Shutdown")
+	defer span.End()
+
+	span.SetIntAttribute("service.shutdown.pre_stop_delay_ms", options.preStopDelay.Milliseconds())
+	span.SetIntAttribute("service.shutdown.drain_timeout_ms", options.drainTimeout.Milliseconds())
+	span.SetIntAttribute("service.shutdown.close_timeout_ms", options.closeTimeout.Milliseconds())
+
+	if options.preStopDelay > 0 {
+		log.Info(drainCtx, fmt.Sprintf("Service is waiting %s before shutting down", options.preStopDelay))
+		span.AddEvent("pre_stop")
+
+		select {
+		case <-time.After(options.preStopDelay):
+		case <-drainCtx.Done():
+		}
+	}
+
+	log.Info(drainCtx, "Service is shutting down")
+	span.AddEvent("close")
+	if err := s.shutdown(drainCtx, options.closeTimeout); err != nil {
+		span.RecordError("failed to gracefully shut down the service", err)
+		stack.WithChildren(err)
+	}
+
+	if stack.HasChildren() {
+		return stack
+	}
+
+	log.Info(drainCtx, "Service has been shut down")
+	return nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+closing is a mock integration blocking when closed, until it is released.
+*/
+type closing struct {
+	mock
+	closing chan struct{}
+	release chan struct{}
+}
+
+func (c *closing) Close(ctx context.Context) error {
+	close(c.closing)
+	<-c.release
+	return nil
+}
+
+func TestService_Run(t *testing.T) {
+	testcases := []struct {
+		fails   int
+		signals int
+		exited  bool
+	}{
+		{
+			fails:   1,
+			signals: 1,
+			exited:  false,
+		},
+		{
+			fails:   1,
+			signals: 2,
+			exited:  true,
+		},
+		{
+			fails:   0,
+			signals: 2,
+			exited:  true,
+		},
+	}
+
+	defer func() {
+		exit = os.Exit
+	}()
+
+	for _, tc := range testcases {
+		exited := make(chan int, 1)
+		exit = func(code int) {
+			exited <- code
+		}
+
+		inte := &closing{
+			mock:    mock{name: "postgres", fails: tc.fails},
+			closing: make(chan struct{}),
+			release: make(chan struct{}),
+		}
+
+		s := New()
+		require.NoError(t, s.Attach(inte))
+
+		ran := make(chan error, 1)
+		go func() {
+			ran <- s.Run(context.Background(), WithSignalsOnRun(os.Interrupt))
+		}()
+
+		process, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+
+		// The first signal stops the service if it is still serving.
+		signals := tc.signals
+		if tc.fails == 0 {
+			require.NoError(t, s.WaitReady(context.Background()))
+			require.NoError(t, process.Signal(os.Interrupt))
+			signals--
+		}
+
+		// Pending signals are merged by the operating system, so they are sent one
+		// at a time.
+		<-inte.closing
+		code := -1
+		for i := 0; i < signals && code < 0; i++ {
+			require.NoError(t, process.Signal(os.Interrupt))
+
+			select {
+			case code = <-exited:
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+
+		assert.Equal(t, tc.exited, code == 1)
+
+		close(inte.release)
+		err = <-ran
+		assert.Equal(t, tc.fails > 0, err != nil)
+	}
+}
+
+func TestCloseWithTimeout(t *testing.T) {
+	inte := &closing{
+		mock:    mock{name: "postgres"},
+		closing: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+
+	defer close(inte.release)
+
+	// The context passed is respected even if no timeout is set.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := closeWithTimeout(ctx, inte, 0)
+	assert.ErrorContains(t, err, "Failed to gracefully close integration")
+}
@@ -3,150 +3,354 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	"sync"
 	"syscall"
+	"time"
 
 	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/event"
 	"go.nunchi.studio/helix/integration"
 	"go.nunchi.studio/helix/internal/logger"
+	"go.nunchi.studio/helix/internal/recovery"
 	"go.nunchi.studio/helix/internal/tracer"
 )
 
 /*
-svc is the service being run at the moment. Only one service should be running
-at a time. This is why end-users can't create multiple services in a single Go
-application.
+Service holds some information for a service leveraging helix.go, and manages the
+lifecycle of the integrations attached to it. Most applications only rely on the
+default service through the package functions, such as Attach and Start. Creating
+services with New is mostly useful for running several isolated services in a
+single Go application, such as in tests.
 */
-var svc = new(service)
-
-/*
-service holds some information for the service running.
-*/
-type service struct {
+type Service struct {
 
 	// mutex allows to lock/unlock access to the service when necessary.
 	mutex sync.Mutex
 
 	// isInitialized informs if the service has already been initialized. In other
-	// words this informs if the Init() function has already been called and returned
-	// with no error.
+	// words this informs if the Start() or Run() function has already been called
+	// and the service has not been closed since.
 	isInitialized bool
 
-	// isClosed informs if the service has already been closed. In other words this
-	// informs if the Close() function has already been called and returned with
-	// no error.
+	// isClosed informs if the service has already been closed since it has been
+	// initialized for the last time. In other words this informs if the Close()
+	// function has already been called and returned with no error.
 	isClosed bool
 
+	// isClosing informs if the service is being closed. The mutex is not held while
+	// closing integrations, so this prevents the service from being closed more
+	// than once at a time.
+	isClosing bool
+
 	// integrations is the list of integrations attached to the service.
 	integrations []integration.Integration
+
+	// layers is the index of the integrations attached to the service, sorted by
+	// dependencies when the service is initialized. Integrations of a layer only
+	// depend on integrations of previous layers.
+	layers [][]int
+
+	// ready holds a channel for each integration attached to the service, at the
+	// same index. A channel is closed once its integration is ready.
+	ready []chan struct{}
+
+	// health holds the state of health checks of the service.
+	health health
+
+	// supervision holds the state of supervision of the integrations attached.
+	supervision supervision
+
+	// hooks holds the hooks registered for the lifecycle of the service.
+	hooks hooks
+
+	// panicPolicy is the policy applied when a panic is recovered. Set to
+	// PanicPolicyContinue if empty.
+	panicPolicy PanicPolicy
+}
+
+/*
+New returns a new service, isolated from the default one. Integrations must be
+attached to it with its Attach method.
+*/
+func New() *Service {
+	return &Service{}
 }
 
 /*
 Start initializes the helix service, and starts each integration attached by
-executing their Start function. This returns as soon as an interrupting signal
-is catched or when an integration returns an error while starting it.
+executing their Start function. Integrations are started in order given their
+dependencies: an integration is only started once every integration it depends
+on is ready. This returns as soon as an interrupting signal (SIGINT or SIGTERM)
+is catched, when the context is done, or when an integration returns an error
+while starting it and its supervision policy requires the service to stop. Hooks
+registered with OnBeforeStart and OnAfterStart are executed respectively before
+starting integrations and once every integration is ready.
+
+Use Run instead for managing the full lifecycle of the service, including the
+graceful shutdown.
 */
-func Start(ctx context.Context) error {
-	svc.mutex.Lock()
-	defer svc.mutex.Unlock()
+func (s *Service) Start(ctx context.Context) error {
+
+	layers, err := s.initialize()
+	if err != nil {
+		return err
+	}
+
+	// Create a channel for receiving interrupting signals. The function will then
+	// return as soon as the channel receives a value.
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(done)
+
+	return s.serve(ctx, layers, done)
+}
 
+/*
+serve starts each integration attached to an initialized service, layer by layer.
+It returns as soon as the done channel receives a value, when the context is done,
+or when an integration returns an error while starting it.
+*/
+func (s *Service) serve(ctx context.Context, layers [][]int, done <-chan os.Signal) error {
 	stack := errorstack.New("Failed to initialize the service")
-	if svc.isInitialized {
-		stack.WithValidations(errorstack.Validation{
-			Message: "Service has already been initialized",
-		})
+	integrations, ready := s.readiness()
+
+	// Execute the hooks registered for running before starting integrations. No
+	// need to start anything if one of them fails.
+	err := s.runHooks(ctx, "before start", func(h *hooks) []Hook {
+		return h.beforeStart
+	})
 
+	if err != nil {
+		stack.WithChildren(err)
 		return stack
 	}
 
-	if svc.isClosed {
-		stack.WithValidations(errorstack.Validation{
-			Message: "Cannot initialize a closed service",
-		})
+	// Create a channel for catching integration and hook errors. The function will
+	// then return as soon as one of the channel receives a value.
+	failed := make(chan error, len(integrations)+1)
 
-		return stack
-	}
+	// Also catch panics recovered if the service must stop in such case.
+	s.mutex.Lock()
+	policy := s.panicPolicy
+	s.mutex.Unlock()
 
-	// Create a channel for receiving interrupting signals, and another one for
-	// catching integration errors. The function will then return as soon as one
-	// of the channel receives a value.
-	done := make(chan os.Signal, 1)
-	failed := make(chan error, 1)
+	if policy == PanicPolicyStop {
+		unsubscribe := recovery.Subscribe(failed)
+		defer unsubscribe()
+	}
 
-	// Listen for interrupting signals.
+	// Execute the hooks registered for running once every integration is ready,
+	// as long as the service is serving.
+	stopped := make(chan struct{})
+	defer close(stopped)
 	go func() {
-		signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-		<-done
+		for i := range integrations {
+			select {
+			case <-ready[i]:
+			case <-stopped:
+				return
+			}
+		}
+
+		err := s.runHooks(ctx, "after start", func(h *hooks) []Hook {
+			return h.afterStart
+		})
+
+		if err != nil {
+			failed <- err
+		}
 	}()
 
-	// For each integration attached, execute its Start function. If an error is
-	// encountered, send the error as a child error to the channel.
-	for _, inte := range svc.integrations {
-		go func() {
-			err := inte.Start(ctx)
-			if err != nil {
-				failed <- stack.WithChildren(err)
+	// For each layer, execute the Start function of its integrations. If an error
+	// is encountered, send the error to the channel. Since Start can be blocking,
+	// only wait for the integrations other integrations depend on to be ready
+	// before moving to the next layer.
+	depended := dependedOn(integrations)
+	for _, layer := range layers {
+		for _, i := range layer {
+			go s.start(ctx, i, integrations[i], ready[i], failed)
+		}
+
+		for _, i := range layer {
+			if !depended[integrations[i].String()] {
+				continue
 			}
-		}()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return nil
+			case err := <-failed:
+				stack.WithChildren(err)
+				return stack
+			case <-ready[i]:
+			}
+		}
 	}
 
-	// Return as soon as an interrupting signal is catched or when an integration
-	// returns an error while starting it.
-	svc.isInitialized = true
+	// Return as soon as an interrupting signal is catched, the context is done,
+	// or when an integration or a hook returns an error while starting it.
 	select {
 	case <-done:
 		return nil
-	case <-failed:
+	case <-ctx.Done():
+		return nil
+	case err := <-failed:
+		stack.WithChildren(err)
 		return stack
 	}
 }
 
 /*
-Close tries to gracefully close connections with all integrations. It then tries
-to drain/close the tracer and logger.
+initialize marks the service as initialized, and returns the integrations attached
+sorted into layers given their dependencies. Returns an error if the service can
+not be initialized.
 */
-func Close(ctx context.Context) error {
-	svc.mutex.Lock()
-	defer svc.mutex.Unlock()
+func (s *Service) initialize() ([][]int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	stack := errorstack.New("Failed to gracefully close service's connections")
-	if !svc.isInitialized {
+	stack := errorstack.New("Failed to initialize the service")
+	if s.isInitialized {
 		stack.WithValidations(errorstack.Validation{
-			Message: "Service must first be initialized",
+			Message: "Service has already been initialized",
 		})
 
-		return stack
+		return nil, stack
 	}
 
-	if svc.isClosed {
-		stack.WithValidations(errorstack.Validation{
-			Message: "Service has already been closed",
-		})
+	// Sort integrations given their dependencies. No need to start anything if
+	// the dependency graph is not valid.
+	layers, validations := buildLayers(s.integrations)
+	if len(validations) > 0 {
+		stack.WithValidations(validations...)
+		return nil, stack
+	}
 
-		return stack
+	s.layers = layers
+	s.isInitialized = true
+	s.isClosed = false
+
+	return layers, nil
+}
+
+/*
+Close tries to gracefully close connections with all integrations, in the reverse
+order they have been started. It then tries to drain/close the tracer and logger,
+only for the default service since they are shared across the Go application.
+Once closed, the service can be started again, as long as the integrations attached
+support being started again after being closed.
+*/
+func (s *Service) Close(ctx context.Context) error {
+	return s.shutdown(ctx, 0)
+}
+
+/*
+shutdown tries to gracefully close connections with all integrations, in the
+reverse order they have been started. If timeout is greater than 0, it is the
+maximum duration given to each integration for closing. It then tries to drain/close
+the tracer and logger, only for the default service.
+*/
+func (s *Service) shutdown(ctx context.Context, timeout time.Duration) error {
+	integrations, layers, err := s.closing()
+	if err != nil {
+		return err
 	}
 
-	var wg sync.WaitGroup
-	for _, inte := range svc.integrations {
-		wg.Add(1)
+	// The mutex is not held while closing, since hooks and integrations may rely
+	// on the service, such as HTTP handlers serving health checks while being
+	// drained. Mark the service as not closing anymore once done.
+	defer func() {
+		s.mutex.Lock()
+		s.isClosing = false
+		s.mutex.Unlock()
+	}()
 
-		go func() {
-			defer wg.Done()
+	stack := errorstack.New("Failed to gracefully close service's connections")
 
-			err := inte.Close(ctx)
-			if err != nil {
-				stack.WithChildren(err)
-			}
-		}()
+	// Execute the hooks registered for running before closing integrations. Still
+	// close integrations if one of them fails.
+	err = s.runHooks(ctx, "before close", func(h *hooks) []Hook {
+		return h.beforeClose
+	})
+
+	if err != nil {
+		stack.WithChildren(err)
+	}
+
+	// Send the events buffered before closing integrations, since sinks may rely on
+	// them. Events are also sent once each layer is closed, so events tracked by an
+	// integration being drained — such as by HTTP handlers — are sent before closing
+	// the integrations it depends on. Sinks are shared across the Go application, so
+	// events are only flushed by the default service.
+	var mutex sync.Mutex
+	flush := func() {
+		if s != Default() {
+			return
+		}
+
+		if err := event.Flush(ctx); err != nil {
+			mutex.Lock()
+			stack.WithChildren(err)
+			mutex.Unlock()
+		}
+	}
+
+	flush()
+
+	// Close integrations in the reverse order they have been started: an integration
+	// is only closed once every integration depending on it has been closed.
+	// Integrations of a same layer are closed concurrently.
+	for i := len(layers) - 1; i >= 0; i-- {
+		var wg sync.WaitGroup
+		for _, index := range layers[i] {
+			inte := integrations[index]
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				err := closeWithTimeout(ctx, inte, timeout)
+				if err != nil {
+					mutex.Lock()
+					stack.WithChildren(err)
+					mutex.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+		flush()
+	}
+
+	// Execute the hooks registered for running once integrations are closed.
+	err = s.runHooks(ctx, "after close", func(h *hooks) []Hook {
+		return h.afterClose
+	})
+
+	if err != nil {
+		stack.WithChildren(err)
 	}
 
-	wg.Wait()
 	if stack.HasChildren() {
 		return stack
 	}
 
+	// The tracer and logger are shared across the Go application, so they are only
+	// drained/closed by the default service.
+	if s != Default() {
+		s.mutex.Lock()
+		s.reset()
+		s.mutex.Unlock()
+
+		return nil
+	}
+
 	if tracer.Exporter() != nil {
 		if err := tracer.Exporter().Shutdown(ctx); err != nil {
 			stack.WithChildren(&errorstack.Error{
@@ -181,6 +385,114 @@ func Close(ctx context.Context) error {
 		return stack
 	}
 
-	svc.isClosed = true
+	s.mutex.Lock()
+	s.reset()
+	s.mutex.Unlock()
+
 	return nil
 }
+
+/*
+closing marks the service as being closed, and returns the integrations attached
+along their layers to close. Returns an error if the service can not be closed.
+*/
+func (s *Service) closing() ([]integration.Integration, [][]int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stack := errorstack.New("Failed to gracefully close service's connections")
+	if s.isClosed {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Service has already been closed",
+		})
+
+		return nil, nil, stack
+	}
+
+	if !s.isInitialized {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Service must first be initialized",
+		})
+
+		return nil, nil, stack
+	}
+
+	if s.isClosing {
+		stack.WithValidations(errorstack.Validation{
+			Message: "Service is already being closed",
+		})
+
+		return nil, nil, stack
+	}
+
+	s.isClosing = true
+	return slices.Clone(s.integrations), s.layers, nil
+}
+
+/*
+reset marks the service as closed, and resets the state of integrations so the
+service can be started once again. It must be called while holding the mutex.
+*/
+func (s *Service) reset() {
+	s.ready = make([]chan struct{}, len(s.integrations))
+	for i := range s.ready {
+		s.ready[i] = make(chan struct{})
+	}
+
+	s.health.reset()
+	s.supervision.reset()
+	s.isInitialized = false
+	s.isClosed = true
+}
+
+/*
+closeWithTimeout executes the Close function of an integration. It stops waiting
+for the integration once the context passed is done, or once the timeout is reached
+if greater than 0, even if the integration doesn't respect the context passed.
+*/
+func closeWithTimeout(ctx context.Context, inte integration.Integration, timeout time.Duration) error {
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	defer cancel()
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- closeOnce(ctx, inte)
+	}()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		message := "Integration has not been closed before the context was done"
+		if timeout > 0 {
+			message = fmt.Sprintf("Integration has not been closed within %s", timeout)
+		}
+
+		stack := errorstack.New("Failed to gracefully close integration", errorstack.WithIntegration(inte.String()))
+		stack.WithValidations(errorstack.Validation{
+			Message: message,
+		})
+
+		return stack
+	}
+}
+
+/*
+closeOnce executes the Close function of an integration, and converts a panic
+into an error.
+*/
+func closeOnce(ctx context.Context, inte integration.Integration) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovery.FromPanic(ctx, r, inte.String())
+		}
+	}()
+
+	return inte.Close(ctx)
+}
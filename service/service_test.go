@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.nunchi.studio/helix/event"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Restart(t *testing.T) {
+	s := New()
+
+	postgres := &mock{name: "postgres"}
+	rest := &mock{name: "rest", dependsOn: []string{"postgres"}}
+	assert.NoError(t, s.Attach(postgres))
+	assert.NoError(t, s.Attach(rest))
+
+	for i := 1; i <= 2; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan error, 1)
+		go func() {
+			started <- s.Start(ctx)
+		}()
+
+		readyCtx, readyCancel := context.WithTimeout(context.Background(), time.Second)
+		assert.NoError(t, s.WaitReady(readyCtx))
+		readyCancel()
+
+		cancel()
+		assert.NoError(t, <-started)
+		assert.NoError(t, s.Close(context.Background()))
+		assert.Error(t, s.Close(context.Background()))
+
+		assert.Equal(t, i, postgres.starts)
+		assert.Equal(t, i, rest.closes)
+	}
+}
+
+func TestService_Isolation(t *testing.T) {
+	first := New()
+	second := New()
+
+	inte := &mock{name: "postgres"}
+	assert.NoError(t, first.Attach(inte))
+	assert.Error(t, first.Attach(inte))
+	assert.NoError(t, second.Attach(inte))
+
+	assert.Len(t, first.integrations, 1)
+	assert.Len(t, second.integrations, 1)
+	assert.Empty(t, Default().integrations)
+
+	SetDefault(first)
+	defer SetDefault(New())
+
+	assert.Same(t, first, Default())
+	assert.NoError(t, Attach(&mock{name: "nats"}))
+	assert.Len(t, first.integrations, 2)
+}
+
+func TestService_CloseReentrant(t *testing.T) {
+	s := New()
+
+	inte := &mock{name: "postgres"}
+	assert.NoError(t, s.Attach(inte))
+
+	// The service must still be usable while closing, and must not be closed
+	// more than once at a time.
+	s.OnBeforeClose(func(ctx context.Context) error {
+		report := s.Health(ctx)
+		assert.True(t, report.Initialized)
+		assert.Error(t, s.Close(ctx))
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan error, 1)
+	go func() {
+		started <- s.Start(ctx)
+	}()
+
+	readyCtx, readyCancel := context.WithTimeout(context.Background(), time.Second)
+	assert.NoError(t, s.WaitReady(readyCtx))
+	readyCancel()
+
+	cancel()
+	assert.NoError(t, <-started)
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), time.Second)
+	defer closeCancel()
+
+	assert.NoError(t, s.Close(closeCtx))
+	assert.Equal(t, 1, inte.closes)
+}
+
+/*
+unhashable is an integration that can not be used as a map key, since it holds
+a slice.
+*/
+type unhashable struct {
+	tags []string
+}
+
+func (u unhashable) String() string                          { return "unhashable" }
+func (u unhashable) Start(ctx context.Context) error         { return nil }
+func (u unhashable) Close(ctx context.Context) error         { return nil }
+func (u unhashable) Status(ctx context.Context) (int, error) { return 200, nil }
+
+func TestService_Unhashable(t *testing.T) {
+	s := New()
+	assert.NoError(t, s.Attach(unhashable{tags: []string{"a"}}))
+	assert.NoError(t, s.Attach(&mock{name: "postgres"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan error, 1)
+	go func() {
+		started <- s.Start(ctx)
+	}()
+
+	readyCtx, readyCancel := context.WithTimeout(context.Background(), time.Second)
+	assert.NoError(t, s.WaitReady(readyCtx))
+	readyCancel()
+
+	report := s.Health(context.Background())
+	assert.Equal(t, HealthStatusHealthy, report.Status)
+
+	cancel()
+	assert.NoError(t, <-started)
+	assert.NoError(t, s.Close(context.Background()))
+}
+
+/*
+tracking is a mock integration tracking an event when closed, just like an HTTP
+server draining requests.
+*/
+type tracking struct {
+	mock
+}
+
+func (t *tracking) Close(ctx context.Context) error {
+	t.closes++
+	return event.Track(ctx, event.Event{Name: "closed"})
+}
+
+/*
+backendSink is a Sink failing once the integration it relies on is closed.
+*/
+type backendSink struct {
+	event.MemorySink
+	backend *mock
+}
+
+func (s *backendSink) Send(ctx context.Context, events []event.Event) error {
+	if s.backend.closes > 0 {
+		return errors.New("backend is closed")
+	}
+
+	return s.MemorySink.Send(ctx, events)
+}
+
+func TestService_CloseFlush(t *testing.T) {
+	s := New()
+	SetDefault(s)
+	defer SetDefault(New())
+
+	clickhouse := &mock{name: "clickhouse"}
+	rest := &tracking{mock: mock{name: "rest", dependsOn: []string{"clickhouse"}}}
+	require.NoError(t, s.Attach(clickhouse))
+	require.NoError(t, s.Attach(rest))
+
+	sink := &backendSink{backend: clickhouse}
+	require.NoError(t, event.AddSink("backend", sink, event.WithFlushInterval(time.Hour)))
+	defer event.RemoveSink(context.Background(), "backend")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan error, 1)
+	go func() {
+		started <- s.Start(ctx)
+	}()
+
+	readyCtx, readyCancel := context.WithTimeout(context.Background(), time.Second)
+	assert.NoError(t, s.WaitReady(readyCtx))
+	readyCancel()
+
+	cancel()
+	assert.NoError(t, <-started)
+
+	// Events tracked while closing are sent before closing the integrations sinks
+	// rely on. The error is not checked since the logger can not always be synced
+	// when testing.
+	s.Close(context.Background())
+	assert.Equal(t, 1, rest.closes)
+	assert.Len(t, sink.Events(), 1)
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.nunchi.studio/helix/errorstack"
+	"go.nunchi.studio/helix/integration"
+	"go.nunchi.studio/helix/internal/recovery"
+	"go.nunchi.studio/helix/telemetry/log"
+	"go.nunchi.studio/helix/telemetry/trace"
+)
+
+/*
+Policy is the policy applied by the service when the Start function of an
+integration returns an error.
+*/
+type Policy string
+
+/*
+Policies that can be applied when an integration fails to start.
+*/
+const (
+
+	// PolicyFailFast stops the service as soon as the integration fails to start.
+	// This is the default policy.
+	PolicyFailFast Policy = "fail_fast"
+
+	// PolicyRestart restarts the integration with an exponential backoff. The
+	// service stops once the maximum number of attempts is reached, if any.
+	PolicyRestart Policy = "restart"
+
+	// PolicyIgnore keeps the service running even if the integration fails to
+	// start. The integration is then considered ready — so integrations depending
+	// on it can start — but the service is marked as degraded.
+	PolicyIgnore Policy = "ignore"
+)
+
+/*
+Supervision configures how the service supervises an integration.
+*/
+type Supervision struct {
+
+	// Policy is the policy applied when the integration fails to start.
+	//
+	// Default:
+	//
+	//   PolicyFailFast
+	Policy Policy
+
+	// MaxAttempts is the maximum number of consecutive restarts when Policy is
+	// PolicyRestart. It is reset once the integration is ready again. It is
+	// unlimited if 0.
+	MaxAttempts int
+
+	// InitialBackoff is the duration to wait before the first restart when Policy
+	// is PolicyRestart. It is doubled after each restart.
+	//
+	// Default:
+	//
+	//   1 * time.Second
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum duration to wait between two restarts when Policy
+	// is PolicyRestart.
+	//
+	// Default:
+	//
+	//   30 * time.Second
+	MaxBackoff time.Duration
+}
+
+/*
+backoff returns the duration to wait before the restart at the attempt passed,
+starting at 1.
+*/
+func (sup Supervision) backoff(attempt int) time.Duration {
+	initial := sup.InitialBackoff
+	if initial <= 0 {
+		initial = 1 * time.Second
+	}
+
+	max := sup.MaxBackoff
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+
+	backoff := initial
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+
+	if backoff > max {
+		backoff = max
+	}
+
+	return backoff
+}
+
+/*
+supervision holds the state of supervision of the integrations attached to the
+service.
+*/
+type supervision struct {
+
+	// mutex allows to lock/unlock access to the supervision state.
+	mutex sync.Mutex
+
+	// policies holds the supervision of integrations, by name. An integration not
+	// in the map is supervised with the default policy.
+	policies map[string]Supervision
+
+	// states holds the supervision state of each integration that failed to start
+	// at least once, by index.
+	states map[int]*supervisionState
+}
+
+/*
+supervisionState is the supervision state of a single integration.
+*/
+type supervisionState struct {
+
+	// restarts is the number of times the integration has been restarted.
+	restarts int
+
+	// restarting informs if the integration is waiting to be restarted, or is
+	// restarting.
+	restarting bool
+
+	// ignored informs if the integration failed to start and has been ignored.
+	ignored bool
+
+	// err is the last error returned by the Start function of the integration.
+	err error
+}
+
+/*
+SetSupervision sets how the service supervises an integration, given the name of
+the integration as returned by its String function. By default, the service stops
+as soon as an integration fails to start.
+
+Example:
+
+	service.SetSupervision("temporal", service.Supervision{
+	  Policy:      service.PolicyRestart,
+	  MaxAttempts: 5,
+	})
+*/
+func (s *Service) SetSupervision(name string, sup Supervision) {
+	s.supervision.mutex.Lock()
+	defer s.supervision.mutex.Unlock()
+
+	if s.supervision.policies == nil {
+		s.supervision.policies = make(map[string]Supervision)
+	}
+
+	s.supervision.policies[name] = sup
+}
+
+/*
+start executes the Start function of an integration given its index, and closes
+the ready channel passed once the integration is ready. If the integration
+implements the Readiness interface, it relies on it. Otherwise the integration is
+considered ready as soon as its Start function returns with no error. If an error
+is encountered, the supervision policy of the integration is applied. The error is
+sent to the failed channel if the service must stop.
+*/
+func (s *Service) start(ctx context.Context, index int, inte integration.Integration, ready chan struct{}, failed chan<- error) {
+	markReady := sync.OnceFunc(func() {
+		close(ready)
+	})
+
+	s.supervision.mutex.Lock()
+	sup := s.supervision.policies[inte.String()]
+	s.supervision.mutex.Unlock()
+
+	for attempt := 1; ; attempt++ {
+
+		// The integration is not restarting anymore once ready, even if its Start
+		// function is blocking and therefore doesn't return.
+		var wasReady atomic.Bool
+		err := startOnce(ctx, inte, func() {
+			wasReady.Store(true)
+			markReady()
+			s.supervise(index, func(state *supervisionState) {
+				state.restarting = false
+			})
+		})
+
+		if err == nil {
+			s.supervise(index, func(state *supervisionState) {
+				state.restarting = false
+			})
+
+			return
+		}
+
+		// Only count consecutive failures: an integration that has been ready during
+		// this attempt failed after running, not while starting.
+		if wasReady.Load() {
+			attempt = 1
+		}
+
+		// No need to apply the policy if the service is stopping.
+		if ctx.Err() != nil {
+			failed <- err
+			return
+		}
+
+		_, span := trace.Start(ctx, trace.SpanKindInternal, "Service: Supervision")
+		span.SetStringAttribute("service.integration.name", inte.String())
+		span.SetStringAttribute("service.supervision.policy", string(sup.Policy))
+		span.SetIntAttribute("service.supervision.attempt", int64(attempt))
+		span.RecordError("integration failed to start", err)
+
+		switch {
+		case sup.Policy == PolicyIgnore:
+			s.supervise(index, func(state *supervisionState) {
+				state.ignored = true
+				state.err = err
+			})
+
+			span.AddEvent("ignore")
+			span.End()
+
+			log.Warn(ctx, fmt.Sprintf("Integration %q failed to start and is ignored", inte.String()))
+			markReady()
+			return
+
+		case sup.Policy == PolicyRestart && (sup.MaxAttempts <= 0 || attempt <= sup.MaxAttempts):
+			backoff := sup.backoff(attempt)
+			s.supervise(index, func(state *supervisionState) {
+				state.restarting = true
+				state.restarts++
+				state.err = err
+			})
+
+			span.SetIntAttribute("service.supervision.backoff_ms", backoff.Milliseconds())
+			span.AddEvent("restart")
+			span.End()
+
+			log.Warn(ctx, fmt.Sprintf("Integration %q failed to start, restarting in %s", inte.String(), backoff))
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				failed <- err
+				return
+			}
+
+		default:
+			span.AddEvent("fail")
+			span.End()
+
+			failed <- err
+			return
+		}
+	}
+}
+
+/*
+startOnce executes the Start function of an integration a single time, and calls
+markReady once the integration is ready. markReady is not called once the attempt
+has failed. A panic is converted into an error.
+*/
+func startOnce(ctx context.Context, inte integration.Integration, markReady func()) (err error) {
+
+	// The readiness of the integration is only awaited for this attempt, so the
+	// goroutine doesn't leak if the integration fails to start.
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovery.FromPanic(ctx, r, inte.String())
+		}
+
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	readiness, ok := inte.(integration.Readiness)
+	if ok {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					recovery.FromPanic(ctx, r, inte.String())
+				}
+			}()
+
+			select {
+			case <-readiness.Ready(attemptCtx):
+				if attemptCtx.Err() == nil {
+					markReady()
+				}
+			case <-attemptCtx.Done():
+			}
+		}()
+	} else {
+		defer cancel()
+	}
+
+	err = inte.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		markReady()
+	}
+
+	return nil
+}
+
+/*
+supervise updates the supervision state of an integration given its index.
+*/
+func (s *Service) supervise(index int, update func(state *supervisionState)) {
+	s.supervision.mutex.Lock()
+	defer s.supervision.mutex.Unlock()
+
+	if s.supervision.states == nil {
+		s.supervision.states = make(map[int]*supervisionState)
+	}
+
+	state, exists := s.supervision.states[index]
+	if !exists {
+		state = new(supervisionState)
+		s.supervision.states[index] = state
+	}
+
+	update(state)
+}
+
+/*
+supervised returns the number of restarts of an integration given its index, if
+it has been ignored after failing to start, and an error if the integration is
+not running because of a failure.
+*/
+func (s *Service) supervised(index int, inte integration.Integration) (int, bool, error) {
+	s.supervision.mutex.Lock()
+	defer s.supervision.mutex.Unlock()
+
+	state, exists := s.supervision.states[index]
+	if !exists {
+		return 0, false, nil
+	}
+
+	var stack *errorstack.Error
+	switch {
+	case state.ignored:
+		stack = errorstack.New("Integration failed to start and is ignored", errorstack.WithIntegration(inte.String()))
+	case state.restarting:
+		stack = errorstack.New("Integration failed to start and is restarting", errorstack.WithIntegration(inte.String()))
+		stack.WithValidations(errorstack.Validation{
+			Message: fmt.Sprintf("Integration has been restarted %d times", state.restarts),
+		})
+	default:
+		return state.restarts, false, nil
+	}
+
+	stack.WithChildren(state.err)
+	return state.restarts, state.ignored, stack
+}
+
+/*
+reset clears the supervision state of integrations, such as when the service is
+restarted.
+*/
+func (sup *supervision) reset() {
+	sup.mutex.Lock()
+	defer sup.mutex.Unlock()
+
+	sup.states = nil
+}
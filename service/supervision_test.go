@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupervision_Backoff(t *testing.T) {
+	sup := Supervision{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, sup.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, sup.backoff(2))
+	assert.Equal(t, 800*time.Millisecond, sup.backoff(4))
+	assert.Equal(t, time.Second, sup.backoff(5))
+	assert.Equal(t, time.Second, sup.backoff(50))
+	assert.Equal(t, time.Second, Supervision{}.backoff(1))
+}
+
+func TestSupervision(t *testing.T) {
+	testcases := []struct {
+		supervision Supervision
+		fails       int
+		starts      int
+		failed      bool
+		status      HealthStatus
+		restarts    int
+	}{
+		{
+			supervision: Supervision{},
+			fails:       1,
+			starts:      1,
+			failed:      true,
+		},
+		{
+			supervision: Supervision{
+				Policy:         PolicyRestart,
+				InitialBackoff: time.Millisecond,
+			},
+			fails:    2,
+			starts:   3,
+			status:   HealthStatusHealthy,
+			restarts: 2,
+		},
+		{
+			supervision: Supervision{
+				Policy:         PolicyRestart,
+				MaxAttempts:    1,
+				InitialBackoff: time.Millisecond,
+			},
+			fails:  5,
+			starts: 2,
+			failed: true,
+		},
+		{
+			supervision: Supervision{
+				Policy: PolicyIgnore,
+			},
+			fails:  1,
+			starts: 1,
+			status: HealthStatusDegraded,
+		},
+	}
+
+	for _, tc := range testcases {
+		s := New()
+		s.SetHealthTTL(-1)
+		s.SetSupervision("nats", tc.supervision)
+
+		nats := &mock{name: "nats", fails: tc.fails}
+		assert.NoError(t, s.Attach(&mock{name: "postgres"}))
+		assert.NoError(t, s.Attach(nats))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		started := make(chan error, 1)
+		go func() {
+			started <- s.Start(ctx)
+		}()
+
+		if tc.failed {
+			assert.Error(t, <-started)
+			assert.Equal(t, tc.starts, nats.starts)
+			cancel()
+			continue
+		}
+
+		assert.NoError(t, s.WaitReady(ctx))
+		assert.Equal(t, tc.starts, nats.starts)
+
+		report := s.Health(ctx)
+		assert.Equal(t, tc.status, report.Status)
+		assert.Equal(t, tc.restarts, report.Integrations[1].Restarts)
+
+		cancel()
+		assert.NoError(t, <-started)
+	}
+}
+
+/*
+readiness is a mock integration implementing the Readiness interface, keeping
+track of the context passed to Ready.
+*/
+type readiness struct {
+	mock
+	ready chan struct{}
+	ctx   chan context.Context
+}
+
+func (r *readiness) Ready(ctx context.Context) <-chan struct{} {
+	r.ctx <- ctx
+	return r.ready
+}
+
+func TestStartOnce_Readiness(t *testing.T) {
+	inte := &readiness{
+		mock:  mock{name: "nats", fails: 1},
+		ready: make(chan struct{}),
+		ctx:   make(chan context.Context, 1),
+	}
+
+	// The readiness of a failed attempt is not awaited anymore.
+	err := startOnce(context.Background(), inte, func() {})
+	assert.Error(t, err)
+
+	ctx := <-inte.ctx
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the readiness of the failed attempt to be canceled")
+	}
+
+	// The readiness of a successful attempt is awaited.
+	ready := make(chan struct{})
+	err = startOnce(context.Background(), inte, func() { close(ready) })
+	assert.NoError(t, err)
+
+	ctx = <-inte.ctx
+	assert.NoError(t, ctx.Err())
+
+	close(inte.ready)
+	<-ready
+}
+
+/*
+blocking is a mock integration implementing the Readiness interface, with a Start
+function blocking while running. It fails a given number of times before being
+ready, then crashes a given number of times after being ready.
+*/
+type blocking struct {
+	name    string
+	fails   int
+	crashes int
+
+	mutex  sync.Mutex
+	starts int
+	ready  chan struct{}
+}
+
+func (b *blocking) String() string                          { return b.name }
+func (b *blocking) Close(ctx context.Context) error         { return nil }
+func (b *blocking) Status(ctx context.Context) (int, error) { return 200, nil }
+
+func (b *blocking) Ready(ctx context.Context) <-chan struct{} {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.ready
+}
+
+func (b *blocking) Start(ctx context.Context) error {
+	b.mutex.Lock()
+	b.starts++
+	starts := b.starts
+	if starts > b.fails {
+		close(b.ready)
+	}
+
+	b.mutex.Unlock()
+
+	switch {
+	case starts <= b.fails:
+		return errors.New("failed to start")
+
+	case starts <= b.fails+b.crashes:
+		time.Sleep(10 * time.Millisecond)
+
+		b.mutex.Lock()
+		b.ready = make(chan struct{})
+		b.mutex.Unlock()
+
+		return errors.New("crashed")
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+func TestSupervision_Readiness(t *testing.T) {
+	testcases := []struct {
+		fails    int
+		crashes  int
+		restarts int
+	}{
+		{
+			fails:    1,
+			restarts: 1,
+		},
+		{
+			fails:    1,
+			crashes:  2,
+			restarts: 3,
+		},
+	}
+
+	for _, tc := range testcases {
+		s := New()
+		s.SetHealthTTL(-1)
+		s.SetSupervision("rest", Supervision{
+			Policy:         PolicyRestart,
+			MaxAttempts:    1,
+			InitialBackoff: time.Millisecond,
+		})
+
+		rest := &blocking{
+			name:    "rest",
+			fails:   tc.fails,
+			crashes: tc.crashes,
+			ready:   make(chan struct{}),
+		}
+
+		assert.NoError(t, s.Attach(rest))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		started := make(chan error, 1)
+		go func() {
+			started <- s.Start(ctx)
+		}()
+
+		// The integration is healthy once ready again, even if its Start function
+		// never returns. Restarts are only limited when consecutive.
+		assert.Eventually(t, func() bool {
+			report := s.Health(ctx)
+			return report.Status == HealthStatusHealthy && report.Integrations[0].Restarts == tc.restarts
+		}, 500*time.Millisecond, 10*time.Millisecond)
+
+		cancel()
+		assert.NoError(t, <-started)
+	}
+}
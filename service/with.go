@@ -0,0 +1,113 @@
+package service
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+/*
+runOptions holds the options set when running a service with Run.
+*/
+type runOptions struct {
+
+	// signals are the interrupting signals to listen to.
+	signals []os.Signal
+
+	// preStopDelay is the duration to wait after an interrupting signal is catched
+	// and before closing integrations.
+	preStopDelay time.Duration
+
+	// drainTimeout is the maximum duration for the whole shutdown, including the
+	// pre-stop delay.
+	drainTimeout time.Duration
+
+	// closeTimeout is the maximum duration given to each integration for closing.
+	closeTimeout time.Duration
+}
+
+/*
+defaultRunOptions returns the default options used by Run, before applying the
+ones passed by the client.
+*/
+func defaultRunOptions() *runOptions {
+	return &runOptions{
+		signals:      []os.Signal{os.Interrupt, syscall.SIGINT, syscall.SIGTERM},
+		preStopDelay: 0,
+		drainTimeout: 30 * time.Second,
+		closeTimeout: 0,
+	}
+}
+
+/*
+WithOnRun allows to set optional values when running a service with Run.
+*/
+type WithOnRun func(opts *runOptions)
+
+/*
+WithSignalsOnRun overrides the interrupting signals to listen to for shutting
+down the service.
+
+Default:
+
+	[]os.Signal{os.Interrupt, syscall.SIGINT, syscall.SIGTERM}
+*/
+func WithSignalsOnRun(signals ...os.Signal) WithOnRun {
+	return func(opts *runOptions) {
+		if len(signals) > 0 {
+			opts.signals = signals
+		}
+	}
+}
+
+/*
+WithPreStopDelayOnRun sets a duration to wait after an interrupting signal is
+catched and before closing integrations. This gives time to orchestrators such
+as Kubernetes to propagate the endpoint removal, so no new requests are routed
+to the service while it is shutting down.
+
+Default:
+
+	0
+*/
+func WithPreStopDelayOnRun(delay time.Duration) WithOnRun {
+	return func(opts *runOptions) {
+		if delay >= 0 {
+			opts.preStopDelay = delay
+		}
+	}
+}
+
+/*
+WithDrainTimeoutOnRun sets the maximum duration for the whole shutdown, including
+the pre-stop delay. Once reached, the context passed to integrations' Close
+function is done.
+
+Default:
+
+	30 * time.Second
+*/
+func WithDrainTimeoutOnRun(timeout time.Duration) WithOnRun {
+	return func(opts *runOptions) {
+		if timeout > 0 {
+			opts.drainTimeout = timeout
+		}
+	}
+}
+
+/*
+WithCloseTimeoutOnRun sets the maximum duration given to each integration for
+closing. Once reached, the integration is considered as failed to close and the
+service moves on, even if the integration doesn't respect its context.
+
+Default:
+
+	0 (only limited by the drain timeout)
+*/
+func WithCloseTimeoutOnRun(timeout time.Duration) WithOnRun {
+	return func(opts *runOptions) {
+		if timeout >= 0 {
+			opts.closeTimeout = timeout
+		}
+	}
+}
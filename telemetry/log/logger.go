@@ -2,48 +2,111 @@ package log
 
 import (
 	"context"
+	"errors"
+	"strings"
 
+	"go.nunchi.studio/helix/errorstack"
 	"go.nunchi.studio/helix/internal/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 /*
 Debug logs a message at the debug level. It tries to extract a trace from the
-context to add "trace_id" and "span_id" fields to the log.
+context to add "trace_id" and "span_id" fields to the log. Errors passed are
+added as an "error" field.
 */
-func Debug(ctx context.Context, msg string) {
-	logger.Logger().Debug(msg, logger.FromContextToZapFields(ctx)...)
+func Debug(ctx context.Context, msg string, errs ...error) {
+	logger.Logger().Debug(msg, fields(ctx, errs)...)
 }
 
 /*
 Info logs a message at the info level. It tries to extract a trace from the
-context to add "trace_id" and "span_id" fields to the log.
+context to add "trace_id" and "span_id" fields to the log. Errors passed are
+added as an "error" field.
 */
-func Info(ctx context.Context, msg string) {
-	logger.Logger().Info(msg, logger.FromContextToZapFields(ctx)...)
+func Info(ctx context.Context, msg string, errs ...error) {
+	logger.Logger().Info(msg, fields(ctx, errs)...)
 }
 
 /*
 Warn logs a message at the warn level. It tries to extract a trace from the
-context to add "trace_id" and "span_id" fields to the log.
+context to add "trace_id" and "span_id" fields to the log. Errors passed are
+added as an "error" field.
 */
-func Warn(ctx context.Context, msg string) {
-	logger.Logger().Warn(msg, logger.FromContextToZapFields(ctx)...)
+func Warn(ctx context.Context, msg string, errs ...error) {
+	logger.Logger().Warn(msg, fields(ctx, errs)...)
 }
 
 /*
 Error logs a message at the error level. It tries to extract a trace from the
-context to add "trace_id" and "span_id" fields to the log.
+context to add "trace_id" and "span_id" fields to the log. Errors passed are
+added as an "error" field.
 */
-func Error(ctx context.Context, msg string) {
-	logger.Logger().Error(msg, logger.FromContextToZapFields(ctx)...)
+func Error(ctx context.Context, msg string, errs ...error) {
+	logger.Logger().Error(msg, fields(ctx, errs)...)
 }
 
 /*
 Fatal logs a message at the fatal level. It tries to extract a trace from the
-context to add "trace_id" and "span_id" fields to the log.
+context to add "trace_id" and "span_id" fields to the log. Errors passed are
+added as an "error" field.
 
 The logger then calls os.Exit(1).
 */
-func Fatal(ctx context.Context, msg string) {
-	logger.Logger().Fatal(msg, logger.FromContextToZapFields(ctx)...)
+func Fatal(ctx context.Context, msg string, errs ...error) {
+	logger.Logger().Fatal(msg, fields(ctx, errs)...)
+}
+
+/*
+fields returns the fields of a log, given the context and errors passed. If an
+*errorstack.Error is found in the errors, the "error" field is the complete tree
+of errors as a structured object. If it is wrapped by other errors, the text of
+the wrappers is added as an "error_context" field. Otherwise, the "error" field
+is the standard zap error field.
+*/
+func fields(ctx context.Context, errs []error) []zapcore.Field {
+	fields := logger.FromContextToZapFields(ctx)
+
+	err := errors.Join(errs...)
+	if err == nil {
+		return fields
+	}
+
+	if len(errs) == 1 {
+		err = errs[0]
+	}
+
+	var stack *errorstack.Error
+	if !errors.As(err, &stack) {
+		return append(fields, zap.Error(err))
+	}
+
+	// Keep the messages of the wrappers when the error is not directly an
+	// *errorstack.Error, without altering the tree of the one found.
+	if stack != err {
+		fields = append(fields, zap.String("error_context", wrapping(err, stack)))
+	}
+
+	return append(fields, zap.Object("error", stack))
+}
+
+/*
+wrapping returns the text added by the errors wrapping the *errorstack.Error passed,
+such as "failed to charge customer" for an error created with:
+
+	fmt.Errorf("failed to charge customer: %w", stack)
+
+The complete text of the error is returned if the wrappers don't only prefix the
+message of the *errorstack.Error.
+*/
+func wrapping(err error, stack *errorstack.Error) string {
+	msg := err.Error()
+	prefix, found := strings.CutSuffix(msg, stack.Error())
+	if !found || prefix == "" {
+		return msg
+	}
+
+	return strings.TrimRight(prefix, ": ")
 }
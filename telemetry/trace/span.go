@@ -1,10 +1,14 @@
 package trace
 
 import (
+	"errors"
 	"fmt"
 
+	"go.nunchi.studio/helix/errorstack"
+
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -103,12 +107,22 @@ func (s *Span) SetFloatAttribute(key string, value float64) {
 }
 
 /*
-RecordError will record the error as an exception span event for this Span.
+RecordError will record the error as an exception span event for this Span. If
+the error is or wraps an *errorstack.Error with a call stack captured, the stack
+is added as "exception.stacktrace" to the event.
 */
 func (s *Span) RecordError(msg string, err error) {
 	s.hasError = true
 
-	s.client.RecordError(err)
+	var opts []trace.EventOption
+	var stack *errorstack.Error
+	if errors.As(err, &stack) && len(stack.StackTrace()) > 0 {
+		opts = append(opts, trace.WithAttributes(
+			attribute.String("exception.stacktrace", fmt.Sprintf("%+v", stack)),
+		))
+	}
+
+	s.client.RecordError(err, opts...)
 	s.client.SetStatus(codes.Error, msg)
 }
 
@@ -133,9 +147,18 @@ after this method is called. Therefore, updates to the Span are not allowed afte
 this method has been called.
 */
 func (s *Span) End() {
-	if !s.hasError {
+	if !s.hasError && !hasErrorStatus(s.client) {
 		s.client.SetStatus(codes.Ok, "")
 	}
 
 	s.client.End()
 }
+
+/*
+hasErrorStatus indicates if an error status has already been set on the span
+without using this package, such as when recovering from a panic.
+*/
+func hasErrorStatus(span trace.Span) bool {
+	ro, ok := span.(sdktrace.ReadOnlySpan)
+	return ok && ro.Status().Code == codes.Error
+}
@@ -42,3 +42,27 @@ func Start(ctx context.Context, kind SpanKind, name string) (context.Context, *S
 
 	return ctx, s
 }
+
+/*
+BaggagePolicy controls which fields of an Event are propagated across services
+through a Baggage, and how they are truncated to fit in the size limits of the
+W3C Baggage specification.
+*/
+type BaggagePolicy = tracer.BaggagePolicy
+
+/*
+SetBaggagePolicy sets the policy applied when propagating an Event through a
+Baggage, such as when starting a Span. This shall be called once, before serving
+the service. Returns an error if a pattern or the maximum size is not valid.
+
+Example:
+
+	err := trace.SetBaggagePolicy(trace.BaggagePolicy{
+	  Deny:     []string{"event.ip", "event.user_agent", "event.location.*"},
+	  Priority: []string{"event.name", "event.user_id", "event.subscriptions.*"},
+	  MaxBytes: 4096,
+	})
+*/
+func SetBaggagePolicy(policy BaggagePolicy) error {
+	return tracer.SetBaggagePolicy(policy)
+}